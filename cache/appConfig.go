@@ -4,22 +4,22 @@ import (
 	"errors"
 	"sync"
 
-	"github.com/Jeffail/gabs"
+	"github.com/Snakeyesz/snek-bot/models"
 )
 
 var (
-	appConfigs      *gabs.Container
+	appConfigs      *models.AppConfig
 	appConfigsMutex sync.RWMutex
 )
 
-func SetAppConfig(config *gabs.Container) {
+func SetAppConfig(config *models.AppConfig) {
 	appConfigsMutex.Lock()
 	defer appConfigsMutex.Unlock()
 
 	appConfigs = config
 }
 
-func GetAppConfig() *gabs.Container {
+func GetAppConfig() *models.AppConfig {
 	appConfigsMutex.RLock()
 	defer appConfigsMutex.RUnlock()
 
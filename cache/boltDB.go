@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltDB      *bolt.DB
+	boltDBMutex sync.RWMutex
+)
+
+func SetBoltDB(db *bolt.DB) {
+	boltDBMutex.Lock()
+	defer boltDBMutex.Unlock()
+
+	boltDB = db
+}
+
+func GetBoltDB() *bolt.DB {
+	boltDBMutex.RLock()
+	defer boltDBMutex.RUnlock()
+
+	if boltDB == nil {
+		panic(errors.New("BoltDB was not opened before use"))
+	}
+
+	return boltDB
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+var (
+	imageSource      models.ImageSource
+	imageSourceMutex sync.RWMutex
+)
+
+func SetImageSource(source models.ImageSource) {
+	imageSourceMutex.Lock()
+	defer imageSourceMutex.Unlock()
+
+	imageSource = source
+}
+
+func GetImageSource() models.ImageSource {
+	imageSourceMutex.RLock()
+	defer imageSourceMutex.RUnlock()
+
+	if imageSource == nil {
+		panic(errors.New("Image source was not set before use"))
+	}
+
+	return imageSource
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+var (
+	repositories      *models.Repositories
+	repositoriesMutex sync.RWMutex
+)
+
+func SetRepositories(r *models.Repositories) {
+	repositoriesMutex.Lock()
+	defer repositoriesMutex.Unlock()
+
+	repositories = r
+}
+
+func GetRepositories() *models.Repositories {
+	repositoriesMutex.RLock()
+	defer repositoriesMutex.RUnlock()
+
+	if repositories == nil {
+		panic(errors.New("Repositories were not set before use"))
+	}
+
+	return repositories
+}
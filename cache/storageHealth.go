@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"sync"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+var (
+	storageHealth      models.StorageHealth
+	storageHealthMutex sync.RWMutex
+)
+
+func SetStorageHealth(health models.StorageHealth) {
+	storageHealthMutex.Lock()
+	defer storageHealthMutex.Unlock()
+
+	storageHealth = health
+}
+
+func GetStorageHealth() models.StorageHealth {
+	storageHealthMutex.RLock()
+	defer storageHealthMutex.RUnlock()
+
+	return storageHealth
+}
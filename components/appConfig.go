@@ -1,19 +1,390 @@
 package components
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
-	"github.com/Jeffail/gabs"
+	"github.com/BurntSushi/toml"
 	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
 	"github.com/Snakeyesz/snek-bot/utils"
+	"gopkg.in/yaml.v2"
 )
 
-// Load app configuration from config file
+const (
+	CONFIG_FILES_ENV      = "SNEK_BOT_CONFIG"
+	DEFAULT_MONGO_DB_HOST = "localhost"
+	DEFAULT_BOLT_DB_PATH  = "snek-bot.db"
+
+	DEFAULT_BIASGAME_LOCAL_IMAGES_PATH = "biasgame-images"
+
+	DEFAULT_BIASGAME_GAME_SIZE         = 32
+	DEFAULT_BIASGAME_MULTI_ROUND_DELAY = 5  // seconds
+	DEFAULT_BIASGAME_INACTIVE_TIMEOUT  = 30 // minutes
+	DEFAULT_BIASGAME_INACTIVE_ROUNDS   = 3
+	MIN_BIASGAME_GAME_SIZE             = 9 // games need to get down to a top eight for the winner bracket
+)
+
+var defaultBiasGameGameSizes = []int{32, 64, 128, 256}
+
+// checked in order when no config files are set in the environment, the first one found is loaded
+var defaultConfigFiles = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// environment variables that override a config value. the key is only used in error messages
+var configEnvOverrides = []struct {
+	env   string
+	key   string
+	apply func(config *models.AppConfig, value string) error
+}{
+	{"SNEK_BOT_DISCORD_TOKEN", "discord_bot.token", func(config *models.AppConfig, value string) error {
+		config.DiscordBot.Token = value
+		return nil
+	}},
+	{"SNEK_BOT_OWNER_IDS", "discord_bot.owner_ids", func(config *models.AppConfig, value string) error {
+		config.DiscordBot.OwnerIDs = strings.Split(value, ",")
+		return nil
+	}},
+	{"SNEK_BOT_STORAGE_BACKEND", "storage.backend", func(config *models.AppConfig, value string) error {
+		config.Storage.Backend = value
+		return nil
+	}},
+	{"SNEK_BOT_BOLT_DB_PATH", "storage.bolt_db_path", func(config *models.AppConfig, value string) error {
+		config.Storage.BoltDBPath = value
+		return nil
+	}},
+	{"SNEK_BOT_MONGO_DB_ADDRESS", "mongo_db.hosts.<active_host>.db_address", func(config *models.AppConfig, value string) error {
+		host := config.GetMongoDBHost()
+		host.DBAddress = value
+		config.MongoDB.Hosts[config.MongoDB.ActiveHost] = host
+		return nil
+	}},
+	{"SNEK_BOT_MONGO_DB_NAME", "mongo_db.hosts.<active_host>.db_name", func(config *models.AppConfig, value string) error {
+		host := config.GetMongoDBHost()
+		host.DBName = value
+		config.MongoDB.Hosts[config.MongoDB.ActiveHost] = host
+		return nil
+	}},
+	{"SNEK_BOT_MONGO_DB_USERNAME", "mongo_db.hosts.<active_host>.username", func(config *models.AppConfig, value string) error {
+		host := config.GetMongoDBHost()
+		host.Username = value
+		config.MongoDB.Hosts[config.MongoDB.ActiveHost] = host
+		return nil
+	}},
+	{"SNEK_BOT_MONGO_DB_PASSWORD", "mongo_db.hosts.<active_host>.password", func(config *models.AppConfig, value string) error {
+		host := config.GetMongoDBHost()
+		host.Password = value
+		config.MongoDB.Hosts[config.MongoDB.ActiveHost] = host
+		return nil
+	}},
+	{"SNEK_BOT_GOOGLE_DRIVE_CREDENTIALS", "google_drive", func(config *models.AppConfig, value string) error {
+		// path to the json key file of the service account
+		credentials, err := ioutil.ReadFile(value)
+		if err != nil {
+			return err
+		}
+
+		config.GoogleDrive = models.GoogleDriveConfig{}
+		return json.Unmarshal(credentials, &config.GoogleDrive)
+	}},
+	{"SNEK_BOT_GOOGLE_DRIVE_CLIENT_EMAIL", "google_drive.client_email", func(config *models.AppConfig, value string) error {
+		config.GoogleDrive.ClientEmail = value
+		return nil
+	}},
+	{"SNEK_BOT_GOOGLE_DRIVE_PRIVATE_KEY", "google_drive.private_key", func(config *models.AppConfig, value string) error {
+		config.GoogleDrive.PrivateKey = value
+		return nil
+	}},
+}
+
+var discordIDRegex = regexp.MustCompile(`^\d+$`)
+
+// LoadAppConfig loads the app configuration from the config files and environment.
+//  panics with every missing or invalid key if the configuration isn't valid
+//
+//  SNEK_BOT_CONFIG can be a comma separated list of json, yaml, or toml files.
+//   later files override the keys set in earlier ones
+//  environment variables override the files, see configEnvOverrides
 func LoadAppConfig() {
 	fmt.Println("Loading app Config...")
 
-	data, err := gabs.ParseJSONFile("config.json")
+	config, err := readAppConfig()
 	utils.PanicCheck(err)
 
-	cache.SetAppConfig(data)
+	cache.SetAppConfig(config)
+}
+
+// readAppConfig reads, overrides, and validates the app configuration
+func readAppConfig() (*models.AppConfig, error) {
+	config := &models.AppConfig{}
+
+	configFiles, err := getConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, configFile := range configFiles {
+		err = decodeConfigFile(configFile, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// defaults for keys that aren't required
+	if config.Storage.Backend == "" {
+		config.Storage.Backend = models.STORAGE_BACKEND_MONGO_DB
+	}
+	if config.Storage.BoltDBPath == "" {
+		config.Storage.BoltDBPath = DEFAULT_BOLT_DB_PATH
+	}
+	if config.MongoDB.ActiveHost == "" {
+		config.MongoDB.ActiveHost = DEFAULT_MONGO_DB_HOST
+	}
+	if config.MongoDB.Hosts == nil {
+		config.MongoDB.Hosts = make(map[string]models.MongoDBHostConfig)
+	}
+	if config.BiasGame.ImageSource == "" {
+		config.BiasGame.ImageSource = models.IMAGE_SOURCE_GOOGLE_DRIVE
+	}
+	if config.BiasGame.LocalImagesPath == "" {
+		config.BiasGame.LocalImagesPath = DEFAULT_BIASGAME_LOCAL_IMAGES_PATH
+	}
+	if len(config.BiasGame.GameSizes) == 0 {
+		config.BiasGame.GameSizes = defaultBiasGameGameSizes
+	}
+	if config.BiasGame.DefaultGameSize == 0 {
+		config.BiasGame.DefaultGameSize = DEFAULT_BIASGAME_GAME_SIZE
+	}
+	if config.BiasGame.MultiRoundDelaySeconds == 0 {
+		config.BiasGame.MultiRoundDelaySeconds = DEFAULT_BIASGAME_MULTI_ROUND_DELAY
+	}
+	if config.BiasGame.InactiveGameTimeoutMinutes == 0 {
+		config.BiasGame.InactiveGameTimeoutMinutes = DEFAULT_BIASGAME_INACTIVE_TIMEOUT
+	}
+	if config.BiasGame.MultiInactiveRounds == 0 {
+		config.BiasGame.MultiInactiveRounds = DEFAULT_BIASGAME_INACTIVE_ROUNDS
+	}
+
+	err = applyConfigEnvOverrides(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, validateAppConfig(config)
+}
+
+// getConfigFiles returns the config files set in the environment or the first default config file found
+func getConfigFiles() ([]string, error) {
+	if configFiles := os.Getenv(CONFIG_FILES_ENV); configFiles != "" {
+		return strings.Split(configFiles, ","), nil
+	}
+
+	for _, configFile := range defaultConfigFiles {
+		if _, err := os.Stat(configFile); err == nil {
+			return []string{configFile}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no config file found, expected one of %s or a list of files in %s",
+		strings.Join(defaultConfigFiles, ", "), CONFIG_FILES_ENV)
+}
+
+// decodeConfigFile decodes the config file into the config based on its extension.
+//  keys missing from the file keep their current value
+func decodeConfigFile(configFile string, config *models.AppConfig) error {
+	data, err := ioutil.ReadFile(strings.TrimSpace(configFile))
+	if err != nil {
+		return fmt.Errorf("unable to read config file %s: %s", configFile, err.Error())
+	}
+
+	switch strings.ToLower(filepath.Ext(configFile)) {
+	case ".json":
+		err = json.Unmarshal(data, config)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, config)
+	case ".toml":
+		err = toml.Unmarshal(data, config)
+	default:
+		return fmt.Errorf("unknown config file type %s, must be json, yaml, or toml", configFile)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %s", configFile, err.Error())
+	}
+
+	return nil
+}
+
+// applyConfigEnvOverrides sets the config values of any override environment variables that are set
+func applyConfigEnvOverrides(config *models.AppConfig) error {
+	for _, override := range configEnvOverrides {
+		value, ok := os.LookupEnv(override.env)
+		if !ok {
+			continue
+		}
+
+		if err := override.apply(config, value); err != nil {
+			return fmt.Errorf("invalid %s for %s: %s", override.env, override.key, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validateAppConfig checks the config for missing or invalid keys. every problem found is in the error
+func validateAppConfig(config *models.AppConfig) error {
+	var problems []string
+	missing := func(key string, env string) {
+		problems = append(problems, fmt.Sprintf("%s is missing, set it in the config or with %s", key, env))
+	}
+	invalid := func(key string, reason string) {
+		problems = append(problems, fmt.Sprintf("%s is invalid, %s", key, reason))
+	}
+
+	// discord
+	if config.DiscordBot.Token == "" {
+		missing("discord_bot.token", "SNEK_BOT_DISCORD_TOKEN")
+	}
+	for i, ownerID := range config.DiscordBot.OwnerIDs {
+		if !discordIDRegex.MatchString(ownerID) {
+			invalid(fmt.Sprintf("discord_bot.owner_ids[%d]", i), "must be a discord user id")
+		}
+	}
+
+	// storage, mongo is only needed when it's the storage backend
+	switch config.Storage.Backend {
+	case models.STORAGE_BACKEND_MONGO_DB:
+		hostKey := "mongo_db.hosts." + config.MongoDB.ActiveHost
+		if _, ok := config.MongoDB.Hosts[config.MongoDB.ActiveHost]; !ok {
+			invalid("mongo_db.active_host", fmt.Sprintf("there is no host named %s in mongo_db.hosts", config.MongoDB.ActiveHost))
+		} else {
+			if config.GetMongoDBHost().DBAddress == "" {
+				missing(hostKey+".db_address", "SNEK_BOT_MONGO_DB_ADDRESS")
+			}
+			if config.GetMongoDBHost().DBName == "" {
+				missing(hostKey+".db_name", "SNEK_BOT_MONGO_DB_NAME")
+			}
+			if (config.GetMongoDBHost().Username == "") != (config.GetMongoDBHost().Password == "") {
+				invalid(hostKey+".username", "username and password must be set together")
+			}
+			if config.GetMongoDBHost().TimeoutSeconds < 0 || config.GetMongoDBHost().PoolLimit < 0 || config.GetMongoDBHost().HealthCheckSeconds < 0 {
+				invalid(hostKey, "timeout_seconds, pool_limit, and health_check_seconds can't be negative")
+			}
+		}
+	case models.STORAGE_BACKEND_BOLT_DB, models.STORAGE_BACKEND_MEMORY:
+	default:
+		invalid("storage.backend", fmt.Sprintf("must be %s, %s, or %s",
+			models.STORAGE_BACKEND_MONGO_DB, models.STORAGE_BACKEND_BOLT_DB, models.STORAGE_BACKEND_MEMORY))
+	}
+
+	// google drive is only needed when it's the biasgame image source
+	switch config.BiasGame.ImageSource {
+	case models.IMAGE_SOURCE_GOOGLE_DRIVE:
+		if config.GoogleDrive.Type != "" && config.GoogleDrive.Type != "service_account" {
+			invalid("google_drive.type", "must be service_account")
+		}
+		if config.GoogleDrive.ClientEmail == "" {
+			missing("google_drive.client_email", "SNEK_BOT_GOOGLE_DRIVE_CLIENT_EMAIL or SNEK_BOT_GOOGLE_DRIVE_CREDENTIALS")
+		}
+		if config.GoogleDrive.PrivateKey == "" {
+			missing("google_drive.private_key", "SNEK_BOT_GOOGLE_DRIVE_PRIVATE_KEY or SNEK_BOT_GOOGLE_DRIVE_CREDENTIALS")
+		}
+	case models.IMAGE_SOURCE_LOCAL:
+	default:
+		invalid("biasgame.image_source", fmt.Sprintf("must be %s or %s", models.IMAGE_SOURCE_GOOGLE_DRIVE, models.IMAGE_SOURCE_LOCAL))
+	}
+
+	// cooldowns
+	rateLimits := map[string]models.RateLimitConfig{
+		"cooldowns.user":    config.Cooldowns.User,
+		"cooldowns.channel": config.Cooldowns.Channel,
+		"cooldowns.guild":   config.Cooldowns.Guild,
+	}
+	for commandPath, rateLimit := range config.Cooldowns.Commands {
+		rateLimits["cooldowns.commands."+commandPath] = rateLimit
+	}
+	var rateLimitKeys []string
+	for key := range rateLimits {
+		rateLimitKeys = append(rateLimitKeys, key)
+	}
+	sort.Strings(rateLimitKeys)
+
+	for _, key := range rateLimitKeys {
+		rateLimit := rateLimits[key]
+		if rateLimit.Uses < 0 || rateLimit.Seconds < 0 {
+			invalid(key, "uses and seconds can't be negative")
+		} else if rateLimit.Uses > 0 && rateLimit.Seconds == 0 {
+			invalid(key, "seconds must be set when uses is set")
+		}
+	}
+
+	// biasgame, the game is turned off if the drive folders of the google drive image source aren't set
+	if config.BiasGame.SuggestionChannelID != "" && !discordIDRegex.MatchString(config.BiasGame.SuggestionChannelID) {
+		invalid("biasgame.suggestion_channel_id", "must be a discord channel id")
+	}
+	problems = append(problems, validateBiasGameGuildConfig("biasgame", config.BiasGame.ForGuild(""))...)
+
+	var guildIDs []string
+	for guildID := range config.BiasGame.Guilds {
+		guildIDs = append(guildIDs, guildID)
+	}
+	sort.Strings(guildIDs)
+
+	for _, guildID := range guildIDs {
+		key := "biasgame.guilds." + guildID
+		if !discordIDRegex.MatchString(guildID) {
+			invalid(key, "must be a discord guild id")
+		}
+		problems = append(problems, validateBiasGameGuildConfig(key, config.BiasGame.ForGuild(guildID))...)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &appConfigError{problems: problems}
+}
+
+// validateBiasGameGuildConfig returns the problems with the biasgame settings of a guild, or the global settings
+func validateBiasGameGuildConfig(key string, settings models.BiasGameGuildConfig) []string {
+	var problems []string
+
+	defaultSizeAllowed := false
+	for _, gameSize := range settings.GameSizes {
+		if gameSize < MIN_BIASGAME_GAME_SIZE {
+			problems = append(problems, fmt.Sprintf("%s.game_sizes is invalid, %d is less than the smallest game size of %d", key, gameSize, MIN_BIASGAME_GAME_SIZE))
+		}
+		if gameSize == settings.DefaultGameSize {
+			defaultSizeAllowed = true
+		}
+	}
+
+	if !defaultSizeAllowed {
+		problems = append(problems, fmt.Sprintf("%s.default_game_size is invalid, %d is not one of the game_sizes", key, settings.DefaultGameSize))
+	}
+	if settings.MultiRoundDelaySeconds < 0 {
+		problems = append(problems, fmt.Sprintf("%s.multi_round_delay_seconds is invalid, can't be negative", key))
+	}
+	if settings.InactiveGameTimeoutMinutes < 0 {
+		problems = append(problems, fmt.Sprintf("%s.inactive_game_timeout_minutes is invalid, can't be negative", key))
+	}
+	if settings.MultiInactiveRounds < 0 {
+		problems = append(problems, fmt.Sprintf("%s.multi_inactive_rounds is invalid, can't be negative", key))
+	}
+
+	return problems
+}
+
+// appConfigError lists every problem found with the config
+type appConfigError struct {
+	problems []string
+}
+
+func (e *appConfigError) Error() string {
+	return fmt.Sprintf("invalid app config, %d problem(s) found:\n  - %s", len(e.problems), strings.Join(e.problems, "\n  - "))
 }
@@ -0,0 +1,37 @@
+package components
+
+import (
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/storage"
+	"github.com/Snakeyesz/snek-bot/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// bolt only allows one process to open the file, fail instead of waiting forever if another bot has it open
+	BOLT_DB_OPEN_TIMEOUT = time.Second * 5
+)
+
+// ConnectBoltDB opens the bolt database file, creating it if it doesn't exist.
+//  then caches the database and applies any new migrations
+func ConnectBoltDB() {
+	db, err := bolt.Open(cache.GetAppConfig().Storage.BoltDBPath, 0600, &bolt.Options{Timeout: BOLT_DB_OPEN_TIMEOUT})
+	utils.PanicCheck(err)
+
+	err = storage.CreateBoltDBBuckets(db)
+	utils.PanicCheck(err)
+
+	cache.SetBoltDB(db)
+
+	// bring the database up to date before anything uses it
+	err = storage.RunBoltDBMigrations()
+	utils.PanicCheck(err)
+}
+
+// DisconnectBoltDB closes the cached database.
+//  should only be called once nothing else needs the database
+func DisconnectBoltDB() {
+	cache.GetBoltDB().Close()
+}
@@ -10,16 +10,12 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-var (
-	prefix string = "!"
-)
-
 // initialize and set up discord bot
 func InitDiscordBot() {
 	fmt.Println("Initializing discord bot...")
 
 	// init discord
-	discord, err := discordgo.New("Bot " + cache.GetAppConfig().Path("discord_bot.token").Data().(string))
+	discord, err := discordgo.New("Bot " + cache.GetAppConfig().DiscordBot.Token)
 	utils.PanicCheck(err)
 	cache.SetDiscordSession(discord)
 
@@ -33,6 +29,12 @@ func addEventHandlers(discord *discordgo.Session) {
 	// add handlers
 	discord.AddHandler(messageCreate)
 	discord.AddHandler(botOnReactionAdd)
+	discord.AddHandler(botOnReactionRemove)
+	discord.AddHandler(botOnMessageUpdate)
+	discord.AddHandler(botOnMessageDelete)
+	discord.AddHandler(botOnGuildMemberAdd)
+	discord.AddHandler(botOnGuildCreate)
+	discord.AddHandler(botOnVoiceStateUpdate)
 }
 
 /**********************************
@@ -47,22 +49,23 @@ func messageCreate(s *discordgo.Session, msg *discordgo.MessageCreate) {
 		return
 	}
 
-	// If the text has the bots prefix, call plugins
-	if strings.HasPrefix(msg.Content, prefix) {
-		trimmedMessage := strings.TrimPrefix(msg.Content, prefix)
-
-		// bot command
-		command := strings.SplitN(trimmedMessage, " ", 2)[0]
+	// If the text has the guilds prefix or mentions the bot, call plugins
+	trimmedMessage, ok := trimCommandPrefix(s, msg.Message)
+	if !ok {
+		return
+	}
 
-		// check for user input passed the command
-		userText := ""
-		if len(strings.SplitN(trimmedMessage, " ", 2)) > 1 {
-			userText = strings.SplitN(trimmedMessage, " ", 2)[1]
-		}
+	// bot command
+	command := strings.SplitN(trimmedMessage, " ", 2)[0]
 
-		// pass command to plugin handler
-		modules.CallBotPlugin(command, userText, msg.Message)
+	// check for user input passed the command
+	userText := ""
+	if len(strings.SplitN(trimmedMessage, " ", 2)) > 1 {
+		userText = strings.SplitN(trimmedMessage, " ", 2)[1]
 	}
+
+	// pass command to plugin handler
+	modules.CallBotPlugin(command, userText, msg.Message)
 }
 
 // Called everytime a reaction is added to any message
@@ -73,3 +76,63 @@ func botOnReactionAdd(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
 
 	modules.CallBotPluginOnReactionAdd(r)
 }
+
+// Called everytime a reaction is removed from any message
+func botOnReactionRemove(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+	if r.UserID == s.State.User.ID {
+		return
+	}
+
+	modules.CallBotPluginOnReactionRemove(r)
+}
+
+// Called everytime a message is edited on any channel that the autenticated bot has access to.
+func botOnMessageUpdate(s *discordgo.Session, msg *discordgo.MessageUpdate) {
+
+	// Ignore edits of messages created by the bot itself. embed updates have no author
+	if msg.Author != nil && msg.Author.ID == s.State.User.ID {
+		return
+	}
+
+	modules.CallBotPluginOnMessageUpdate(msg)
+}
+
+// Called everytime a message is deleted on any channel that the autenticated bot has access to.
+func botOnMessageDelete(s *discordgo.Session, msg *discordgo.MessageDelete) {
+	modules.CallBotPluginOnMessageDelete(msg)
+}
+
+// Called everytime a user joins a guild the bot is in
+func botOnGuildMemberAdd(s *discordgo.Session, member *discordgo.GuildMemberAdd) {
+	modules.CallBotPluginOnGuildMemberAdd(member)
+}
+
+// Called when the bot joins a guild, or when a guild becomes available after connecting
+func botOnGuildCreate(s *discordgo.Session, guild *discordgo.GuildCreate) {
+	modules.CallBotPluginOnGuildCreate(guild)
+}
+
+// Called everytime a user joins, leaves, or changes their state in a voice channel
+func botOnVoiceStateUpdate(s *discordgo.Session, voiceState *discordgo.VoiceStateUpdate) {
+	modules.CallBotPluginOnVoiceStateUpdate(voiceState)
+}
+
+// trimCommandPrefix removes the guilds command prefix or a mention of the bot from the start of the message.
+//   returns false if the message does not start with either
+func trimCommandPrefix(s *discordgo.Session, msg *discordgo.Message) (string, bool) {
+
+	// each guild can have its own prefix
+	prefix := utils.GetGuildPrefix(utils.GetGuildIDFromMessage(msg))
+	if strings.HasPrefix(msg.Content, prefix) {
+		return strings.TrimPrefix(msg.Content, prefix), true
+	}
+
+	// the bot can also be called by mentioning it. nickname mentions have a ! before the id
+	for _, mention := range []string{"<@" + s.State.User.ID + ">", "<@!" + s.State.User.ID + ">"} {
+		if strings.HasPrefix(msg.Content, mention) {
+			return strings.TrimSpace(strings.TrimPrefix(msg.Content, mention)), true
+		}
+	}
+
+	return "", false
+}
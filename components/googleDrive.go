@@ -2,6 +2,7 @@ package components
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"golang.org/x/oauth2/google"
@@ -20,7 +21,8 @@ func InitGoogleDrive() {
 	appConfigs := cache.GetAppConfig()
 
 	// load drive configs
-	driveConfigs := appConfigs.Path("google_drive").Bytes()
+	driveConfigs, err := json.Marshal(appConfigs.GoogleDrive)
+	utils.PanicCheck(err)
 	config, err := google.JWTConfigFromJSON(driveConfigs, drive.DriveScope)
 	utils.PanicCheck(err)
 
@@ -2,18 +2,76 @@ package components
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/Jeffail/gabs"
 	"github.com/Snakeyesz/snek-bot/cache"
 	"github.com/Snakeyesz/snek-bot/utils"
 )
 
+const (
+	I18N_FILE = "assets/i18n.json"
+)
+
 // Load i18n cache from json file
 func Loadi18nTranslations() {
 	fmt.Println("Loading i18n file...")
 
-	json, err := gabs.ParseJSONFile("assets/i18n.json")
+	translations, err := readi18nTranslations()
 	utils.PanicCheck(err)
 
-	cache.Seti18nTranslations(json)
+	cache.Seti18nTranslations(translations)
+}
+
+// readi18nTranslations reads and validates the i18n file.
+//  every translation must be a string or a list of strings to pick from at random
+func readi18nTranslations() (*gabs.Container, error) {
+	translations, err := gabs.ParseJSONFile(I18N_FILE)
+	if err != nil {
+		return nil, fmt.Errorf("invalid i18n file %s: %s", I18N_FILE, err.Error())
+	}
+
+	sections, ok := translations.Data().(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid i18n file %s: must be a json object", I18N_FILE)
+	}
+
+	var problems []string
+	for key, value := range sections {
+		problems = append(problems, validatei18nValue(key, value)...)
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid i18n file %s, %d problem(s) found:\n  - %s", I18N_FILE, len(problems), strings.Join(problems, "\n  - "))
+	}
+
+	return translations, nil
+}
+
+// validatei18nValue returns a problem for each translation under the key that isn't a string or list of strings
+func validatei18nValue(key string, value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return nil
+	case []interface{}:
+		if len(value) == 0 {
+			return []string{key + " is an empty list"}
+		}
+		for _, item := range value {
+			if _, ok := item.(string); !ok {
+				return []string{key + " must only contain strings"}
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		var problems []string
+		for childKey, childValue := range value {
+			problems = append(problems, validatei18nValue(key+"."+childKey, childValue)...)
+		}
+		return problems
+	default:
+		return []string{key + " must be a string, a list of strings, or an object"}
+	}
 }
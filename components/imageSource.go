@@ -0,0 +1,24 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/storage"
+)
+
+// InitImageSource sets up the configured biasgame image source and caches it.
+//  google drive is only connected to when it's the image source
+func InitImageSource() {
+	source := cache.GetAppConfig().BiasGame.ImageSource
+	fmt.Println("Initializing image source: " + source)
+
+	switch source {
+	case models.IMAGE_SOURCE_LOCAL:
+		cache.SetImageSource(storage.NewLocalImageSource(cache.GetAppConfig().BiasGame.LocalImagesPath))
+	default:
+		InitGoogleDrive()
+		cache.SetImageSource(storage.NewGoogleDriveImageSource())
+	}
+}
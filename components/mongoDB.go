@@ -1,30 +1,126 @@
 package components
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/models"
 	"github.com/Snakeyesz/snek-bot/utils"
 
 	"github.com/globalsign/mgo"
 
 	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/storage"
+)
+
+const (
+	DEFAULT_MONGO_DB_TIMEOUT               = time.Second * 10
+	DEFAULT_MONGO_DB_HEALTH_CHECK_INTERVAL = time.Second * 30
 )
 
 // ConnectMongoDB connects and logs in to mongo database.
-//  then caches the session and database
+//  then caches the session and database and applies any new migrations
 func ConnectMongoDB() {
 
 	// get host info
-	dbHostAddr := cache.GetAppConfig().Path("mongo_db.hosts.localhost.db_address").Data().(string)
-	dbName := cache.GetAppConfig().Path("mongo_db.hosts.localhost.db_name").Data().(string)
+	host := cache.GetAppConfig().GetMongoDBHost()
 
 	// connect to db
-	session, err := mgo.DialWithInfo(&mgo.DialInfo{
-		Addrs: []string{
-			dbHostAddr,
-		},
-	})
+	session, err := mgo.DialWithInfo(getMongoDBDialInfo(host))
 	utils.PanicCheck(err)
 
 	// save session and database
 	cache.SetMongoDBSession(session)
-	cache.SetMongoDB(session.DB(dbName))
+	cache.SetMongoDB(session.DB(host.DBName))
+	cache.SetStorageHealth(models.StorageHealth{
+		Backend:     models.STORAGE_BACKEND_MONGO_DB,
+		Healthy:     true,
+		LastChecked: time.Now(),
+	})
+
+	// bring the database up to date before anything uses it
+	err = storage.RunMongoDBMigrations()
+	utils.PanicCheck(err)
+}
+
+// DisconnectMongoDB closes the cached session.
+//  should only be called once nothing else needs the database
+func DisconnectMongoDB() {
+	cache.GetMongoDBSession().Close()
+}
+
+// MonitorMongoDB pings the database on an interval, reconnecting when the connection is lost.
+//  the result of each check is cached as the storage health. never returns
+func MonitorMongoDB() {
+	interval := DEFAULT_MONGO_DB_HEALTH_CHECK_INTERVAL
+	if seconds := cache.GetAppConfig().GetMongoDBHost().HealthCheckSeconds; seconds > 0 {
+		interval = time.Duration(seconds * float64(time.Second))
+	}
+
+	for {
+		time.Sleep(interval)
+		checkMongoDBHealth()
+	}
+}
+
+// checkMongoDBHealth pings the database and updates the cached storage health
+func checkMongoDBHealth() {
+	defer utils.RecoverPanic("", "checkMongoDBHealth")
+
+	health := cache.GetStorageHealth()
+	health.LastChecked = time.Now()
+
+	session := cache.GetMongoDBSession().Copy()
+	err := session.Ping()
+	session.Close()
+
+	if err == nil {
+		if !health.Healthy {
+			fmt.Printf("MongoDB connection restored after %s\n", time.Since(health.DownSince).Round(time.Second))
+		}
+
+		health.Healthy = true
+		health.DownSince = time.Time{}
+		cache.SetStorageHealth(health)
+		return
+	}
+
+	if health.Healthy {
+		fmt.Println("MongoDB connection lost: ", err.Error())
+		health.DownSince = health.LastChecked
+	}
+	health.Healthy = false
+	health.LastError = err.Error()
+	cache.SetStorageHealth(health)
+
+	// drop the broken sockets, new session copies will reconnect
+	cache.GetMongoDBSession().Refresh()
+}
+
+// getMongoDBDialInfo returns the connection options of the host
+func getMongoDBDialInfo(host models.MongoDBHostConfig) *mgo.DialInfo {
+	dialInfo := &mgo.DialInfo{
+		Addrs:          host.GetAddresses(),
+		Database:       host.DBName,
+		Username:       host.Username,
+		Password:       host.Password,
+		Source:         host.AuthDB,
+		ReplicaSetName: host.ReplicaSet,
+		Timeout:        DEFAULT_MONGO_DB_TIMEOUT,
+		PoolLimit:      host.PoolLimit,
+	}
+
+	if host.TimeoutSeconds > 0 {
+		dialInfo.Timeout = time.Duration(host.TimeoutSeconds * float64(time.Second))
+	}
+
+	if host.TLS {
+		dialInfo.DialServer = func(address *mgo.ServerAddr) (net.Conn, error) {
+			return tls.Dial("tcp", address.String(), &tls.Config{})
+		}
+	}
+
+	return dialInfo
 }
@@ -0,0 +1,116 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/modules"
+	"github.com/Snakeyesz/snek-bot/utils"
+)
+
+const (
+	CONFIG_WATCH_INTERVAL = time.Second * 5
+)
+
+// ReloadConfigs re-reads the app config and i18n translations and swaps them in if both are valid.
+//  on an error the current config and translations are kept.
+//  plugins are told about the reload once both are swapped
+func ReloadConfigs() error {
+	config, err := readAppConfig()
+	if err != nil {
+		return err
+	}
+
+	translations, err := readi18nTranslations()
+	if err != nil {
+		return err
+	}
+
+	warnRestartRequiredChanges(cache.GetAppConfig(), config)
+
+	cache.SetAppConfig(config)
+	cache.Seti18nTranslations(translations)
+
+	modules.ReloadPlugins()
+	return nil
+}
+
+// WatchConfigFiles reloads the configs whenever the config or i18n files are changed. never returns
+func WatchConfigFiles() {
+	lastModified := getConfigModifiedTimes()
+
+	for {
+		time.Sleep(CONFIG_WATCH_INTERVAL)
+
+		modified := getConfigModifiedTimes()
+		if !configFilesChanged(lastModified, modified) {
+			continue
+		}
+		lastModified = modified
+
+		func() {
+			defer utils.RecoverPanic("", "Config file watcher")
+
+			fmt.Println("Config files changed, reloading...")
+			if err := ReloadConfigs(); err != nil {
+				fmt.Println("Config reload failed, the current config is still in use:", err.Error())
+				return
+			}
+			fmt.Println("Config reloaded.")
+		}()
+	}
+}
+
+// getConfigModifiedTimes returns a map of file => last modified time of the config and i18n files
+func getConfigModifiedTimes() map[string]time.Time {
+	files := []string{I18N_FILE}
+	if configFiles, err := getConfigFiles(); err == nil {
+		files = append(files, configFiles...)
+	}
+
+	modifiedTimes := make(map[string]time.Time)
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modifiedTimes[file] = info.ModTime()
+		}
+	}
+
+	return modifiedTimes
+}
+
+// configFilesChanged checks if any file was changed, added, or removed
+func configFilesChanged(before map[string]time.Time, after map[string]time.Time) bool {
+	if len(before) != len(after) {
+		return true
+	}
+
+	for file, modifiedTime := range after {
+		if !before[file].Equal(modifiedTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// warnRestartRequiredChanges logs config changes that are only used when the bot starts
+func warnRestartRequiredChanges(current *models.AppConfig, reloaded *models.AppConfig) {
+	if current.DiscordBot.Token != reloaded.DiscordBot.Token {
+		fmt.Println("discord_bot.token changed, restart the bot to use it")
+	}
+	if current.Storage != reloaded.Storage {
+		fmt.Println("storage changed, restart the bot to use it")
+	}
+	if current.GetMongoDBHost() != reloaded.GetMongoDBHost() {
+		fmt.Println("mongo_db host changed, restart the bot to use it")
+	}
+	if current.GoogleDrive != reloaded.GoogleDrive {
+		fmt.Println("google_drive changed, restart the bot to use it")
+	}
+	if current.BiasGame.ImageSource != reloaded.BiasGame.ImageSource || current.BiasGame.LocalImagesPath != reloaded.BiasGame.LocalImagesPath {
+		fmt.Println("biasgame image source changed, restart the bot to use it")
+	}
+}
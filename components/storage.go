@@ -0,0 +1,70 @@
+package components
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/storage"
+)
+
+// backend the bot connected to, a reloaded config can't change it until the bot restarts
+var connectedStorageBackend string
+
+// ConnectStorage connects to the configured storage backend and caches its repositories
+func ConnectStorage() {
+	backend := cache.GetAppConfig().Storage.Backend
+	fmt.Println("Connecting to storage: " + backend)
+	connectedStorageBackend = backend
+
+	switch backend {
+	case models.STORAGE_BACKEND_BOLT_DB:
+		ConnectBoltDB()
+		cache.SetRepositories(storage.NewBoltDBRepositories())
+	case models.STORAGE_BACKEND_MEMORY:
+		cache.SetRepositories(storage.NewMemoryRepositories())
+	default:
+		ConnectMongoDB()
+		cache.SetRepositories(storage.NewMongoDBRepositories())
+		go MonitorMongoDB()
+		return
+	}
+
+	// embedded backends are always reachable, mongo updates its health as it's checked
+	cache.SetStorageHealth(models.StorageHealth{Backend: backend, Healthy: true, LastChecked: time.Now()})
+}
+
+// DisconnectStorage closes the connection to the storage backend.
+//  should only be called once nothing else needs the storage
+func DisconnectStorage() {
+	switch connectedStorageBackend {
+	case models.STORAGE_BACKEND_MONGO_DB:
+		DisconnectMongoDB()
+	case models.STORAGE_BACKEND_BOLT_DB:
+		DisconnectBoltDB()
+	}
+}
+
+// MigrateMongoDBToBoltDB copies the records in the configured mongo database to the configured bolt database file.
+//  the bot should not be running on the bolt file while migrating
+func MigrateMongoDBToBoltDB() error {
+	host := cache.GetAppConfig().GetMongoDBHost()
+	if host.DBAddress == "" || host.DBName == "" {
+		return fmt.Errorf("the mongo_db host %s needs a db_address and db_name to migrate from", cache.GetAppConfig().MongoDB.ActiveHost)
+	}
+
+	ConnectMongoDB()
+	defer DisconnectMongoDB()
+	ConnectBoltDB()
+	defer DisconnectBoltDB()
+
+	fmt.Printf("Migrating %s on %s to %s...\n", host.DBName, host.DBAddress, cache.GetAppConfig().Storage.BoltDBPath)
+
+	copied, err := storage.MigrateMongoDBToBoltDB()
+	for collection, amount := range copied {
+		fmt.Printf("Copied %d records from %s\n", amount, collection)
+	}
+
+	return err
+}
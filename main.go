@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Snakeyesz/snek-bot/cache"
 	"github.com/Snakeyesz/snek-bot/components"
@@ -12,14 +15,30 @@ import (
 	"github.com/Snakeyesz/snek-bot/utils"
 )
 
+const (
+	// how long plugins get to save their state before the bot closes anyway
+	SHUTDOWN_TIMEOUT = time.Second * 30
+)
+
 // Bot Entry Point
 func main() {
+	migrateToBoltDB := flag.Bool("migrate-mongo-to-bolt", false, "copy the mongo_db records to the bolt_db file and exit")
+	flag.Parse()
 
 	// Initialize and load components
 	components.LoadAppConfig()
+
+	if *migrateToBoltDB {
+		err := components.MigrateMongoDBToBoltDB()
+		utils.PanicCheck(err)
+
+		fmt.Println("Migration is done, set storage.backend to bolt_db to use it.")
+		return
+	}
+
 	components.Loadi18nTranslations()
-	components.InitGoogleDrive()
-	components.ConnectMongoDB()
+	components.InitImageSource()
+	components.ConnectStorage()
 	components.InitDiscordBot() // always load last
 
 	// connect discord bot
@@ -28,7 +47,10 @@ func main() {
 	utils.PanicCheck(err)
 
 	// init plugins after bot is started
-	modules.InitPlugins()
+	modules.InitPlugins(components.ReloadConfigs)
+
+	// reload the config and i18n files when they are changed
+	go components.WatchConfigFiles()
 
 	// Run bot until connection is closed or interupted
 	fmt.Println("Bot is now running.  Press CTRL-C to exit.")
@@ -38,5 +60,24 @@ func main() {
 	// close bot when signal to close is recieved in the botRuntime channel
 	<-botRuntimeCh
 	fmt.Println("Bot is now closeing.")
+
+	// a second signal skips waiting on the plugins
+	go func() {
+		<-botRuntimeCh
+		fmt.Println("Forcing bot to close.")
+		os.Exit(1)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	// plugins need discord and storage to finish games, music, and stats so they are shut down first
+	err = modules.ShutdownPlugins(ctx)
+	if err != nil {
+		fmt.Println("Plugins did not shut down in time:", err.Error())
+	}
+
 	discord.Close()
+	components.DisconnectStorage()
+	fmt.Println("Bot has closed.")
 }
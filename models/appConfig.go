@@ -0,0 +1,185 @@
+package models
+
+import (
+	"strings"
+)
+
+const (
+	STORAGE_BACKEND_MONGO_DB = "mongo_db"
+	STORAGE_BACKEND_BOLT_DB  = "bolt_db" // single file database, no server needed
+	STORAGE_BACKEND_MEMORY   = "memory"  // nothing is saved when the bot closes
+
+	IMAGE_SOURCE_GOOGLE_DRIVE = "google_drive"
+	IMAGE_SOURCE_LOCAL        = "local" // a folder on the bot's machine, no google drive needed
+)
+
+// AppConfig is the configuration of the bot. loaded and validated by components.LoadAppConfig
+type AppConfig struct {
+	DiscordBot  DiscordBotConfig  `json:"discord_bot" yaml:"discord_bot" toml:"discord_bot"`
+	Storage     StorageConfig     `json:"storage" yaml:"storage" toml:"storage"`
+	MongoDB     MongoDBConfig     `json:"mongo_db" yaml:"mongo_db" toml:"mongo_db"`
+	GoogleDrive GoogleDriveConfig `json:"google_drive" yaml:"google_drive" toml:"google_drive"`
+	Cooldowns   CooldownsConfig   `json:"cooldowns" yaml:"cooldowns" toml:"cooldowns"`
+	BiasGame    BiasGameConfig    `json:"biasgame" yaml:"biasgame" toml:"biasgame"`
+}
+
+type DiscordBotConfig struct {
+	Token            string   `json:"token" yaml:"token" toml:"token"`
+	OwnerIDs         []string `json:"owner_ids" yaml:"owner_ids" toml:"owner_ids"`
+	DMErrorsToOwners bool     `json:"dm_errors_to_owners" yaml:"dm_errors_to_owners" toml:"dm_errors_to_owners"`
+}
+
+type StorageConfig struct {
+	// where games, suggestions, and guild settings are saved. mongo_db, bolt_db, or memory
+	Backend string `json:"backend" yaml:"backend" toml:"backend"`
+
+	// file the bolt_db backend saves to, created if it doesn't exist
+	BoltDBPath string `json:"bolt_db_path" yaml:"bolt_db_path" toml:"bolt_db_path"`
+}
+
+type MongoDBConfig struct {
+	// name of the host in Hosts the bot connects to
+	ActiveHost string                       `json:"active_host" yaml:"active_host" toml:"active_host"`
+	Hosts      map[string]MongoDBHostConfig `json:"hosts" yaml:"hosts" toml:"hosts"`
+}
+
+type MongoDBHostConfig struct {
+	// host:port, or a comma separated list of the replica set members
+	DBAddress string `json:"db_address" yaml:"db_address" toml:"db_address"`
+	DBName    string `json:"db_name" yaml:"db_name" toml:"db_name"`
+
+	// credentials are checked against AuthDB, or DBName if not set
+	Username string `json:"username" yaml:"username" toml:"username"`
+	Password string `json:"password" yaml:"password" toml:"password"`
+	AuthDB   string `json:"auth_db" yaml:"auth_db" toml:"auth_db"`
+
+	ReplicaSet string `json:"replica_set" yaml:"replica_set" toml:"replica_set"`
+	TLS        bool   `json:"tls" yaml:"tls" toml:"tls"`
+
+	// zero uses the defaults, a 10 second timeout and the driver's pool limit
+	TimeoutSeconds float64 `json:"timeout_seconds" yaml:"timeout_seconds" toml:"timeout_seconds"`
+	PoolLimit      int     `json:"pool_limit" yaml:"pool_limit" toml:"pool_limit"`
+
+	// how often the connection is checked, and reconnected if it was lost. 30 seconds if not set
+	HealthCheckSeconds float64 `json:"health_check_seconds" yaml:"health_check_seconds" toml:"health_check_seconds"`
+}
+
+// GoogleDriveConfig is the json key of the google service account used to read idol images
+type GoogleDriveConfig struct {
+	Type                    string `json:"type" yaml:"type" toml:"type"`
+	ProjectID               string `json:"project_id" yaml:"project_id" toml:"project_id"`
+	PrivateKeyID            string `json:"private_key_id" yaml:"private_key_id" toml:"private_key_id"`
+	PrivateKey              string `json:"private_key" yaml:"private_key" toml:"private_key"`
+	ClientEmail             string `json:"client_email" yaml:"client_email" toml:"client_email"`
+	ClientID                string `json:"client_id" yaml:"client_id" toml:"client_id"`
+	AuthURI                 string `json:"auth_uri" yaml:"auth_uri" toml:"auth_uri"`
+	TokenURI                string `json:"token_uri" yaml:"token_uri" toml:"token_uri"`
+	AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url" yaml:"auth_provider_x509_cert_url" toml:"auth_provider_x509_cert_url"`
+	ClientX509CertURL       string `json:"client_x509_cert_url" yaml:"client_x509_cert_url" toml:"client_x509_cert_url"`
+}
+
+// CooldownsConfig limits how often commands can be used. a limit with no uses means no limit
+type CooldownsConfig struct {
+	User    RateLimitConfig `json:"user" yaml:"user" toml:"user"`
+	Channel RateLimitConfig `json:"channel" yaml:"channel" toml:"channel"`
+	Guild   RateLimitConfig `json:"guild" yaml:"guild" toml:"guild"`
+
+	// map of command path => limit for each user of the command. ex: "biasgame stats"
+	Commands map[string]RateLimitConfig `json:"commands" yaml:"commands" toml:"commands"`
+}
+
+type RateLimitConfig struct {
+	Uses    int     `json:"uses" yaml:"uses" toml:"uses"`
+	Seconds float64 `json:"seconds" yaml:"seconds" toml:"seconds"`
+}
+
+type BiasGameConfig struct {
+	// where the idol and misc game images are loaded from. google_drive or local
+	ImageSource string `json:"image_source" yaml:"image_source" toml:"image_source"`
+
+	// folder the local image source uses. idol images are in girls/Group_Name/ and boys/Group_Name/, misc game images in misc/
+	LocalImagesPath string `json:"local_images_path" yaml:"local_images_path" toml:"local_images_path"`
+
+	// google drive folders of the idol images and the misc game images
+	GirlsFolderID string `json:"girls_folder_id" yaml:"girls_folder_id" toml:"girls_folder_id"`
+	BoysFolderID  string `json:"boys_folder_id" yaml:"boys_folder_id" toml:"boys_folder_id"`
+	MiscFolderID  string `json:"misc_folder_id" yaml:"misc_folder_id" toml:"misc_folder_id"`
+
+	// channel idol image suggestions are reviewed in. suggestions are turned off if not set
+	SuggestionChannelID string `json:"suggestion_channel_id" yaml:"suggestion_channel_id" toml:"suggestion_channel_id"`
+
+	// custom emojis can be used in the format <:name:id>
+	Emojis BiasGameEmojisConfig `json:"emojis" yaml:"emojis" toml:"emojis"`
+
+	// save the rounds of games that timed out or were quit in the stats as incomplete games
+	RecordAbandonedGames bool `json:"record_abandoned_games" yaml:"record_abandoned_games" toml:"record_abandoned_games"`
+
+	// settings that can be overridden per guild
+	BiasGameGuildConfig `yaml:",inline"`
+
+	// map of guild id => settings for that guild. unset settings use the global value
+	Guilds map[string]BiasGameGuildConfig `json:"guilds" yaml:"guilds" toml:"guilds"`
+}
+
+type BiasGameEmojisConfig struct {
+	SuggestionThanks   string `json:"suggestion_thanks" yaml:"suggestion_thanks" toml:"suggestion_thanks"`
+	SuggestionApproved string `json:"suggestion_approved" yaml:"suggestion_approved" toml:"suggestion_approved"`
+	SuggestionDenied   string `json:"suggestion_denied" yaml:"suggestion_denied" toml:"suggestion_denied"`
+}
+
+type BiasGameGuildConfig struct {
+	GameSizes              []int   `json:"game_sizes" yaml:"game_sizes" toml:"game_sizes"`
+	DefaultGameSize        int     `json:"default_game_size" yaml:"default_game_size" toml:"default_game_size"`
+	MultiRoundDelaySeconds float64 `json:"multi_round_delay_seconds" yaml:"multi_round_delay_seconds" toml:"multi_round_delay_seconds"`
+
+	// single games without a vote for this long are ended
+	InactiveGameTimeoutMinutes float64 `json:"inactive_game_timeout_minutes" yaml:"inactive_game_timeout_minutes" toml:"inactive_game_timeout_minutes"`
+
+	// multi games are ended after this many rounds in a row without a vote
+	MultiInactiveRounds int `json:"multi_inactive_rounds" yaml:"multi_inactive_rounds" toml:"multi_inactive_rounds"`
+}
+
+// GetAddresses returns the address of each mongo server in DBAddress
+func (c MongoDBHostConfig) GetAddresses() []string {
+	var addresses []string
+	for _, address := range strings.Split(c.DBAddress, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses
+}
+
+// GetMongoDBHost returns the mongo host the bot connects to
+func (c *AppConfig) GetMongoDBHost() MongoDBHostConfig {
+	return c.MongoDB.Hosts[c.MongoDB.ActiveHost]
+}
+
+// ForGuild returns the guild settings with any overrides for the guild applied
+func (c BiasGameConfig) ForGuild(guildID string) BiasGameGuildConfig {
+	settings := c.BiasGameGuildConfig
+
+	override, ok := c.Guilds[guildID]
+	if !ok {
+		return settings
+	}
+
+	if len(override.GameSizes) > 0 {
+		settings.GameSizes = override.GameSizes
+	}
+	if override.DefaultGameSize != 0 {
+		settings.DefaultGameSize = override.DefaultGameSize
+	}
+	if override.MultiRoundDelaySeconds != 0 {
+		settings.MultiRoundDelaySeconds = override.MultiRoundDelaySeconds
+	}
+	if override.InactiveGameTimeoutMinutes != 0 {
+		settings.InactiveGameTimeoutMinutes = override.InactiveGameTimeoutMinutes
+	}
+	if override.MultiInactiveRounds != 0 {
+		settings.MultiInactiveRounds = override.MultiInactiveRounds
+	}
+
+	return settings
+}
@@ -9,6 +9,7 @@ import (
 const (
 	BiasGameTable            MongoDbCollection = "biasgame"
 	BiasGameSuggestionsTable MongoDbCollection = "biasgamesuggestions"
+	BiasGameSnapshotsTable   MongoDbCollection = "biasgamesnapshots"
 )
 
 type BiasEntry struct {
@@ -24,8 +25,11 @@ type BiasGameEntry struct {
 	GameWinner   BiasEntry
 	RoundWinners []BiasEntry
 	RoundLosers  []BiasEntry
-	Gender       string // girl, boy, mixed
-	GameType     string // single, multi
+	Gender       string        // girl, boy, mixed
+	GameType     string        // single, multi
+	CreatedAt    time.Time     // when the game finished
+	Duration     time.Duration // how long the game was played
+	Incomplete   bool          // the game timed out or was quit before it had a winner
 }
 
 type BiasGameSuggestionEntry struct {
@@ -33,7 +37,7 @@ type BiasGameSuggestionEntry struct {
 	UserID            string        // user who made the message
 	ProcessedByUserId string
 	Name              string
-	GrouopName        string
+	GroupName         string
 	Gender            string
 	ImageURL          string
 	ChannelID         string // channel suggestion was made in
@@ -43,3 +47,31 @@ type BiasGameSuggestionEntry struct {
 	IdolMatch         bool
 	LastModifiedOn    time.Time
 }
+
+// BiasGameSnapshotEntry is the state of a running game, saved after each round so the game can be restored after a restart
+type BiasGameSnapshotEntry struct {
+	ID                 bson.ObjectId `bson:"_id,omitempty"`
+	GameKey            string        // single games are keyed by user, multi games by channel
+	GameType           string        // single, multi
+	UserID             string        // player of a single game
+	ChannelID          string
+	Gender             string // girl, boy, mixed
+	IdolsRemaining     int
+	RoundDelay         time.Duration // time users have to vote each round of a multi game
+	BiasQueue          []BiasEntry
+	RoundWinners       []BiasEntry
+	RoundLosers        []BiasEntry
+	RoundWinnerSides   []int // 0 if the left idol won the round, 1 for the right. used to undo votes
+	TopEight           []BiasEntry
+	ImageIndexes       []BiasGameImageIndex
+	LastRoundMessageID string
+	StartedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// BiasGameImageIndex is the image of an idol chosen for a game, by the idols file name.
+//  saved as a list since file names have dots, which mongo doesn't allow in field names
+type BiasGameImageIndex struct {
+	FileName string
+	Index    int
+}
@@ -0,0 +1,35 @@
+package models
+
+const (
+	COUNT_GAME_WINNERS  = "gamewinner"
+	COUNT_ROUND_WINNERS = "roundwinners"
+	COUNT_ROUND_LOSERS  = "roundlosers"
+)
+
+// BiasGameStatsQuery asks a GameRepository how often each idol, or group, won or lost in the filtered games
+type BiasGameStatsQuery struct {
+	Filter  BiasGameFilter
+	Count   string // COUNT_GAME_WINNERS, COUNT_ROUND_WINNERS, or COUNT_ROUND_LOSERS
+	ByGroup bool   // count groups instead of idols
+	Limit   int    // only the most counted are returned, zero for all
+}
+
+// BiasGameStats is the result of a BiasGameStatsQuery
+type BiasGameStats struct {
+	TotalGames int         // games that matched the filter
+	Counts     []BiasCount // most counted first
+}
+
+type BiasCount struct {
+	GroupName string
+	Name      string // empty when counted by group
+	Count     int
+}
+
+// UserRanking is how many single player games a user finished and the idol that won the most of them
+type UserRanking struct {
+	UserID      string
+	TotalGames  int
+	TopIdol     BiasEntry
+	TopIdolWins int
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	GuildSettingsTable MongoDbCollection = "guildsettings"
+)
+
+type GuildSettingsEntry struct {
+	ID                  bson.ObjectId `bson:"_id,omitempty"`
+	GuildID             string
+	Prefix              string                        // command prefix, default prefix is used when empty
+	AdminRoleIDs        []string                      // roles that can use admin commands
+	CommandRoleIDs      map[string][]string           // command path => roles allowed to use the command. ex: "biasgame multi"
+	DisabledPlugins     []string                      // plugin names. ex: "music"
+	DisabledCommands    []string                      // command paths. ex: "biasgame multi"
+	ChannelRestrictions map[string]ChannelRestriction // "plugin:name" or "command:path" => channels the plugin or command can be used in
+	Timezone            string                        // time zone name dates are shown and entered in, utc when empty. ex: "America/New_York"
+}
+
+// ChannelRestriction limits the channels a plugin or command can be used in
+type ChannelRestriction struct {
+	AllowedChannelIDs []string // if any are set, can only be used in these channels
+	DeniedChannelIDs  []string // can never be used in these channels
+}
+
+// Copy returns a copy of the settings that doesn't share its lists and maps with the original
+func (s GuildSettingsEntry) Copy() GuildSettingsEntry {
+	settingsCopy := s
+
+	settingsCopy.AdminRoleIDs = append([]string(nil), s.AdminRoleIDs...)
+
+	if s.CommandRoleIDs != nil {
+		settingsCopy.CommandRoleIDs = make(map[string][]string)
+		for commandPath, roleIDs := range s.CommandRoleIDs {
+			settingsCopy.CommandRoleIDs[commandPath] = append([]string(nil), roleIDs...)
+		}
+	}
+
+	settingsCopy.DisabledPlugins = append([]string(nil), s.DisabledPlugins...)
+	settingsCopy.DisabledCommands = append([]string(nil), s.DisabledCommands...)
+
+	if s.ChannelRestrictions != nil {
+		settingsCopy.ChannelRestrictions = make(map[string]ChannelRestriction)
+		for target, restriction := range s.ChannelRestrictions {
+			settingsCopy.ChannelRestrictions[target] = ChannelRestriction{
+				AllowedChannelIDs: append([]string(nil), restriction.AllowedChannelIDs...),
+				DeniedChannelIDs:  append([]string(nil), restriction.DeniedChannelIDs...),
+			}
+		}
+	}
+
+	return settingsCopy
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	IdolRatingsTable MongoDbCollection = "biasgameratings"
+
+	RATING_SCOPE_GLOBAL = "global"
+	RATING_SCOPE_GUILD  = "guild"
+	RATING_SCOPE_USER   = "user"
+)
+
+// IdolRatingEntry is the elo rating of an idol from the rounds of bias games, globally, in a guild, or for a user
+type IdolRatingEntry struct {
+	ID          bson.ObjectId `bson:"_id,omitempty"`
+	Scope       string        // global, guild, user
+	ScopeID     string        // guild or user id, empty for global
+	GroupName   string
+	Name        string
+	Gender      string
+	Rating      float64
+	Wins        int // rounds won
+	Losses      int // rounds lost
+	LastUpdated time.Time
+}
+
+// IdolRatingFilter limits the ratings returned by a RatingRepository
+type IdolRatingFilter struct {
+	Scope   string
+	ScopeID string
+	Gender  string      // empty for any
+	Idols   []BiasEntry // only the ratings of these idols, matched by group and name. empty for any
+}
+
+// Key identifies the rating by its scope and idol
+func (r IdolRatingEntry) Key() string {
+	return r.Scope + "|" + r.ScopeID + "|" + r.GroupName + "|" + r.Name
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"io"
+)
+
+// ImageSource is where the bias game loads and saves its images. set up by components.InitImageSource
+type ImageSource interface {
+	// ListIdolImages returns every idol image. images of the same idol share a file name
+	ListIdolImages() ([]SourceImage, error)
+
+	// ListMiscImages returns the other images the game is drawn with, found by file name. ex: verses.png
+	ListMiscImages() ([]SourceImage, error)
+
+	// FetchImage opens the data of the image, the caller must close it
+	FetchImage(image SourceImage) (io.ReadCloser, error)
+
+	// AddIdolImage saves the png image of the idol and returns it
+	AddIdolImage(gender string, groupName string, name string, data io.Reader) (SourceImage, error)
+
+	// RemoveIdolImage deletes the image, the idol is removed with its last image.
+	//  the game never calls it, it is only for image source implementers and their tests
+	RemoveIdolImage(image SourceImage) error
+}
+
+// SourceImage is an image in an ImageSource. misc images have no group, name, or gender
+type SourceImage struct {
+	ID         string // drive file id, or the path of a local image in the images folder
+	FileName   string // images of the same idol share a file name
+	GroupName  string
+	Name       string
+	Gender     string // girl, boy
+	ViewURL    string // link to look at the image, empty for local images
+	ContentURL string // link the image is downloaded from, empty for local images
+}
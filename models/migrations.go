@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	MigrationsTable MongoDbCollection = "migrations"
+)
+
+// MigrationEntry records a storage migration that was applied
+type MigrationEntry struct {
+	ID        bson.ObjectId `bson:"_id,omitempty"`
+	Version   int
+	Name      string
+	AppliedOn time.Time
+}
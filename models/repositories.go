@@ -0,0 +1,148 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRecordNotFound is returned by repositories when no record matches
+var ErrRecordNotFound = errors.New("record not found")
+
+// Repositories holds the storage used by the bot. set up by components.ConnectStorage
+type Repositories struct {
+	Games         GameRepository
+	Suggestions   SuggestionRepository
+	GuildSettings GuildSettingsRepository
+	Ratings       RatingRepository
+	Snapshots     GameSnapshotRepository
+}
+
+// GameRepository stores finished bias games
+type GameRepository interface {
+	// InsertGame saves the game, an id is given to the game if it doesn't have one
+	InsertGame(game *BiasGameEntry) error
+
+	// FindGames returns every game that matches the filter
+	FindGames(filter BiasGameFilter) ([]BiasGameEntry, error)
+
+	// CountBiases counts the game or round winners or losers of the filtered games
+	CountBiases(query BiasGameStatsQuery) (BiasGameStats, error)
+
+	// RankUsers returns the users with the most filtered games first, up to limit users. zero for all
+	RankUsers(filter BiasGameFilter, limit int) ([]UserRanking, error)
+}
+
+// BiasGameFilter limits the games returned by a GameRepository. empty fields are not filtered on
+type BiasGameFilter struct {
+	GameType     string // single, multi
+	GuildID      string
+	UserID       string
+	WinnerGender string    // girl, boy
+	CreatedFrom  time.Time // games finished at or after this time
+	CreatedTo    time.Time // games finished before this time
+
+	// incomplete games have no winner and are left out unless asked for
+	IncludeIncomplete bool
+}
+
+// SuggestionRepository stores image suggestions for the bias game
+type SuggestionRepository interface {
+	// InsertSuggestion saves the suggestion, an id is given to the suggestion if it doesn't have one
+	InsertSuggestion(suggestion *BiasGameSuggestionEntry) error
+
+	// UpdateSuggestion replaces the saved suggestion with the same id
+	UpdateSuggestion(suggestion *BiasGameSuggestionEntry) error
+
+	// FindSuggestionsByStatus returns the suggestions with the status. unresolved suggestions have no status
+	FindSuggestionsByStatus(status string) ([]*BiasGameSuggestionEntry, error)
+}
+
+// RatingRepository stores the idol ratings calculated from bias game rounds
+type RatingRepository interface {
+	// FindRatings returns the ratings that match the filter, highest rated first, up to limit ratings. zero for all
+	FindRatings(filter IdolRatingFilter, limit int) ([]IdolRatingEntry, error)
+
+	// SaveRatings inserts or replaces the ratings, matched by scope, scope id, group, and idol name
+	SaveRatings(ratings []IdolRatingEntry) error
+
+	// DeleteRatings removes every rating, used before the ratings are rebuilt
+	DeleteRatings() error
+}
+
+// GameSnapshotRepository stores the state of running games
+type GameSnapshotRepository interface {
+	// FindGameSnapshots returns every saved snapshot
+	FindGameSnapshots() ([]BiasGameSnapshotEntry, error)
+
+	// SaveGameSnapshot inserts or replaces the snapshot with the same game key
+	SaveGameSnapshot(snapshot *BiasGameSnapshotEntry) error
+
+	// DeleteGameSnapshot removes the snapshot of the game, if there is one
+	DeleteGameSnapshot(gameKey string) error
+}
+
+// GuildSettingsRepository stores the settings guilds have changed
+type GuildSettingsRepository interface {
+	// FindGuildSettings returns ErrRecordNotFound if the guild has no saved settings
+	FindGuildSettings(guildID string) (GuildSettingsEntry, error)
+
+	// SaveGuildSettings inserts or updates the settings, an id is given to the settings if they don't have one
+	SaveGuildSettings(settings *GuildSettingsEntry) error
+}
+
+// Matches returns true if the game passes the filter
+func (f BiasGameFilter) Matches(game BiasGameEntry) bool {
+	if f.GameType != "" && game.GameType != f.GameType {
+		return false
+	}
+	if f.GuildID != "" && game.GuildID != f.GuildID {
+		return false
+	}
+	if f.UserID != "" && game.UserID != f.UserID {
+		return false
+	}
+	if f.WinnerGender != "" && game.GameWinner.Gender != f.WinnerGender {
+		return false
+	}
+	if !f.CreatedFrom.IsZero() && game.CreatedAt.Before(f.CreatedFrom) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && !game.CreatedAt.Before(f.CreatedTo) {
+		return false
+	}
+	if !f.IncludeIncomplete && game.Incomplete {
+		return false
+	}
+
+	return true
+}
+
+// Matches returns true if the rating passes the filter
+func (f IdolRatingFilter) Matches(rating IdolRatingEntry) bool {
+	if rating.Scope != f.Scope || rating.ScopeID != f.ScopeID {
+		return false
+	}
+	if f.Gender != "" && rating.Gender != f.Gender {
+		return false
+	}
+	if len(f.Idols) == 0 {
+		return true
+	}
+
+	for _, idol := range f.Idols {
+		if rating.GroupName == idol.GroupName && rating.Name == idol.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StorageHealth is the last known state of the connection to the storage backend
+type StorageHealth struct {
+	Backend     string
+	Healthy     bool
+	LastChecked time.Time
+	LastError   string    // error of the last failed check
+	DownSince   time.Time // when the connection was lost, zero while healthy
+}
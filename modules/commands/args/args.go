@@ -0,0 +1,442 @@
+package args
+
+/**
+ * Typed parsing of the arguments given to a command.
+ * Readers take arguments in order, errors are localized so they can be shown to the user as is
+ */
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	userMentionRegex    = regexp.MustCompile(`^<@!?(\d+)>$`)
+	channelMentionRegex = regexp.MustCompile(`^<#(\d+)>$`)
+	roleMentionRegex    = regexp.MustCompile(`^<@&(\d+)>$`)
+)
+
+// Error is a problem with the arguments a user gave. Key is the i18n key of the message
+type Error struct {
+	Key    string
+	Params []interface{}
+}
+
+// Error returns the translated message
+func (e *Error) Error() string {
+	return utils.Geti18nTextF(e.Key, e.Params...)
+}
+
+// Args holds the arguments of a command that haven't been read yet.
+//   once a reader fails every other reader returns its default and Err returns the first error
+type Args struct {
+	positional []string
+	flags      map[string]string
+	usedFlags  map[string]bool
+	err        error
+}
+
+// Parse splits the content of a command into arguments.
+//   quoted text is one argument, --name and --name=value are flags
+func Parse(content string) *Args {
+	a := &Args{
+		flags:     make(map[string]string),
+		usedFlags: make(map[string]bool),
+	}
+
+	var current []rune
+	inQuotes := false
+	quoted := false
+
+	endArgument := func() {
+		if len(current) > 0 || quoted {
+			argument := string(current)
+
+			if !quoted && strings.HasPrefix(argument, "--") && len(argument) > 2 {
+				name, value := argument[2:], ""
+				if index := strings.Index(name, "="); index != -1 {
+					name, value = name[:index], name[index+1:]
+				}
+				a.flags[strings.ToLower(name)] = value
+			} else {
+				a.positional = append(a.positional, argument)
+			}
+		}
+
+		current = nil
+		quoted = false
+	}
+
+	for _, r := range content {
+		switch {
+		case isQuote(r):
+			inQuotes = !inQuotes
+			quoted = true
+		case unicode.IsSpace(r) && !inQuotes:
+			endArgument()
+		default:
+			current = append(current, r)
+		}
+	}
+
+	if inQuotes {
+		a.fail("args.unterminated-quote")
+	}
+	endArgument()
+
+	return a
+}
+
+// Err returns the first error from parsing or reading the arguments
+func (a *Args) Err() error {
+	return a.err
+}
+
+// Len returns how many arguments are left, not counting flags
+func (a *Args) Len() int {
+	return len(a.positional)
+}
+
+// Done returns the first error, or an error if any arguments or flags were never read
+func (a *Args) Done() error {
+	if a.err != nil {
+		return a.err
+	}
+
+	if len(a.positional) > 0 {
+		a.fail("args.unknown-argument", a.positional[0])
+		return a.err
+	}
+
+	for name := range a.flags {
+		if !a.usedFlags[name] {
+			a.fail("args.unknown-argument", "--"+name)
+			return a.err
+		}
+	}
+
+	return nil
+}
+
+/////////////////////////////////
+//    ORDER FREE READERS       //
+/////////////////////////////////
+
+// Flag checks if --name was given
+func (a *Args) Flag(name string) bool {
+	_, ok := a.FlagValue(name)
+	return ok
+}
+
+// FlagValue returns the value of --name=value
+func (a *Args) FlagValue(name string) (string, bool) {
+	name = strings.ToLower(name)
+
+	value, ok := a.flags[name]
+	if ok {
+		a.usedFlags[name] = true
+	}
+
+	return value, ok
+}
+
+// Keyword removes the words of the keyword if they appear together anywhere in the arguments.
+//   the flag form is also accepted, "rounds won" matches --rounds-won
+func (a *Args) Keyword(keyword string) bool {
+	if a.Flag(strings.Replace(keyword, " ", "-", -1)) {
+		return true
+	}
+
+	words := strings.Fields(strings.ToLower(keyword))
+	if len(words) == 0 {
+		return false
+	}
+
+	for start := 0; start+len(words) <= len(a.positional); start++ {
+		if a.wordsMatch(start, words) {
+			a.positional = append(a.positional[:start], a.positional[start+len(words):]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// KeywordIn removes the first argument found anywhere that is one of the keywords and returns the value it maps to.
+//   flags are checked in sorted order, other flags of the keywords are left unread so Done rejects them
+func (a *Args) KeywordIn(keywords map[string]string) (string, bool) {
+	var names []string
+	for keyword := range keywords {
+		names = append(names, keyword)
+	}
+	sort.Strings(names)
+
+	for _, keyword := range names {
+		if a.Flag(keyword) {
+			return keywords[keyword], true
+		}
+	}
+
+	for i, argument := range a.positional {
+		if value, ok := keywords[strings.ToLower(argument)]; ok {
+			a.positional = append(a.positional[:i], a.positional[i+1:]...)
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// KeywordValue removes the words of before, the argument following them, and the words of after
+//   if they appear together anywhere in the arguments, and returns the argument between them.
+//   the flag form is also accepted, KeywordValue("last", "days") matches "last 30 days" and --last-days=30
+func (a *Args) KeywordValue(before string, after string) (string, bool) {
+	beforeWords := strings.Fields(strings.ToLower(before))
+	afterWords := strings.Fields(strings.ToLower(after))
+
+	if value, ok := a.FlagValue(strings.Join(append(append([]string(nil), beforeWords...), afterWords...), "-")); ok {
+		return value, true
+	}
+
+	length := len(beforeWords) + 1 + len(afterWords)
+	for start := 0; start+length <= len(a.positional); start++ {
+		if !a.wordsMatch(start, beforeWords) || !a.wordsMatch(start+len(beforeWords)+1, afterWords) {
+			continue
+		}
+
+		value := a.positional[start+len(beforeWords)]
+		a.positional = append(a.positional[:start], a.positional[start+length:]...)
+		return value, true
+	}
+
+	return "", false
+}
+
+/////////////////////////////////
+//     POSITIONAL READERS      //
+/////////////////////////////////
+
+// String reads the next argument. name is shown to the user if it's missing
+func (a *Args) String(name string) string {
+	argument, ok := a.next(name)
+	if !ok {
+		return ""
+	}
+
+	a.consume()
+	return argument
+}
+
+// OptionalString reads the next argument if there is one
+func (a *Args) OptionalString(def string) string {
+	if a.err != nil || len(a.positional) == 0 {
+		return def
+	}
+
+	return a.String("")
+}
+
+// Rest reads all remaining arguments as one space separated string
+func (a *Args) Rest(name string) string {
+	if _, ok := a.next(name); !ok {
+		return ""
+	}
+
+	rest := strings.Join(a.positional, " ")
+	a.positional = nil
+	return rest
+}
+
+// Int reads the next argument as a number between min and max
+func (a *Args) Int(name string, min int, max int) int {
+	argument, ok := a.next(name)
+	if !ok {
+		return 0
+	}
+
+	number, err := strconv.Atoi(argument)
+	if err != nil {
+		a.fail("args.not-a-number", argument, name)
+		return 0
+	}
+
+	if number < min || number > max {
+		a.fail("args.number-out-of-range", name, min, max)
+		return 0
+	}
+
+	a.consume()
+	return number
+}
+
+// OptionalInt reads the next argument as a number if it is one.
+//   a number outside of min and max is still an error
+func (a *Args) OptionalInt(name string, min int, max int, def int) int {
+	if a.err != nil || len(a.positional) == 0 {
+		return def
+	}
+
+	if _, err := strconv.Atoi(a.positional[0]); err != nil {
+		return def
+	}
+
+	return a.Int(name, min, max)
+}
+
+// Enum reads the next argument as one of the choices and returns the value it maps to
+func (a *Args) Enum(name string, choices map[string]string) string {
+	argument, ok := a.next(name)
+	if !ok {
+		return ""
+	}
+
+	value, ok := choices[strings.ToLower(argument)]
+	if !ok {
+		a.fail("args.invalid-choice", argument, name, listChoices(choices))
+		return ""
+	}
+
+	a.consume()
+	return value
+}
+
+// OptionalEnum reads the next argument if it is one of the choices
+func (a *Args) OptionalEnum(name string, choices map[string]string, def string) string {
+	if a.err != nil || len(a.positional) == 0 {
+		return def
+	}
+
+	if _, ok := choices[strings.ToLower(a.positional[0])]; !ok {
+		return def
+	}
+
+	return a.Enum(name, choices)
+}
+
+// User reads the next argument as a user mention and returns the user id
+func (a *Args) User(name string) string {
+	return a.mention(name, userMentionRegex, "args.not-a-user")
+}
+
+// OptionalUser reads the next argument if it is a user mention
+func (a *Args) OptionalUser(name string) (string, bool) {
+	return a.optionalMention(name, userMentionRegex, "args.not-a-user")
+}
+
+// Channel reads the next argument as a channel mention and returns the channel id
+func (a *Args) Channel(name string) string {
+	return a.mention(name, channelMentionRegex, "args.not-a-channel")
+}
+
+// OptionalChannel reads the next argument if it is a channel mention
+func (a *Args) OptionalChannel(name string) (string, bool) {
+	return a.optionalMention(name, channelMentionRegex, "args.not-a-channel")
+}
+
+// Role reads the next argument as a role mention and returns the role id
+func (a *Args) Role(name string) string {
+	return a.mention(name, roleMentionRegex, "args.not-a-role")
+}
+
+// OptionalRole reads the next argument if it is a role mention
+func (a *Args) OptionalRole(name string) (string, bool) {
+	return a.optionalMention(name, roleMentionRegex, "args.not-a-role")
+}
+
+// SendError tells the user what was wrong with their arguments and how to see the usage of the command
+func SendError(msg *discordgo.Message, err error, commandPath string) {
+	prefix := utils.GetGuildPrefix(utils.GetGuildIDFromMessage(msg))
+
+	utils.SendMessage(msg.ChannelID, err.Error()+"\n"+utils.Geti18nTextF("args.see-help", prefix, commandPath))
+}
+
+/////////////////////////////////
+//     PRIVATE FUNCTIONS       //
+/////////////////////////////////
+
+// next returns the next argument without reading it, a missing argument is an error
+func (a *Args) next(name string) (string, bool) {
+	if a.err != nil {
+		return "", false
+	}
+
+	if len(a.positional) == 0 {
+		a.fail("args.missing", name)
+		return "", false
+	}
+
+	return a.positional[0], true
+}
+
+// consume removes the next argument
+func (a *Args) consume() {
+	a.positional = a.positional[1:]
+}
+
+// wordsMatch checks if the arguments starting at start are the words, ignoring case
+func (a *Args) wordsMatch(start int, words []string) bool {
+	if start+len(words) > len(a.positional) {
+		return false
+	}
+
+	for i, word := range words {
+		if strings.ToLower(a.positional[start+i]) != word {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *Args) mention(name string, mentionRegex *regexp.Regexp, errorKey string) string {
+	argument, ok := a.next(name)
+	if !ok {
+		return ""
+	}
+
+	match := mentionRegex.FindStringSubmatch(argument)
+	if match == nil {
+		a.fail(errorKey, argument, name)
+		return ""
+	}
+
+	a.consume()
+	return match[1]
+}
+
+func (a *Args) optionalMention(name string, mentionRegex *regexp.Regexp, errorKey string) (string, bool) {
+	if a.err != nil || len(a.positional) == 0 || !mentionRegex.MatchString(a.positional[0]) {
+		return "", false
+	}
+
+	return a.mention(name, mentionRegex, errorKey), true
+}
+
+// fail keeps the first error
+func (a *Args) fail(key string, params ...interface{}) {
+	if a.err == nil {
+		a.err = &Error{Key: key, Params: params}
+	}
+}
+
+// listChoices returns the choices sorted and comma separated
+func listChoices(choices map[string]string) string {
+	var names []string
+	for name := range choices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// isQuote checks for straight and curly double quotes. phones often insert curly quotes
+func isQuote(r rune) bool {
+	return r == '"' || r == '“' || r == '”'
+}
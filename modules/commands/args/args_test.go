@@ -0,0 +1,238 @@
+package args
+
+import (
+	"reflect"
+	"testing"
+)
+
+// errorKey returns the i18n key of the error, empty if there is none
+func errorKey(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.(*Error).Key
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		content    string
+		positional []string
+		flags      map[string]string
+		err        string
+	}{
+		{"", nil, map[string]string{}, ""},
+		{"  one   two ", []string{"one", "two"}, map[string]string{}, ""},
+		{`"red velvet" irene`, []string{"red velvet", "irene"}, map[string]string{}, ""},
+		{"“red velvet” irene", []string{"red velvet", "irene"}, map[string]string{}, ""},
+		{`"" empty`, []string{"", "empty"}, map[string]string{}, ""},
+		{`"--not-a-flag"`, []string{"--not-a-flag"}, map[string]string{}, ""},
+		{"--Multi --last-days=30 --notes=a=b", nil, map[string]string{"multi": "", "last-days": "30", "notes": "a=b"}, ""},
+		{"-- word", []string{"--", "word"}, map[string]string{}, ""},
+		{`"red velvet irene`, []string{"red velvet irene"}, map[string]string{}, "args.unterminated-quote"},
+	}
+
+	for _, test := range tests {
+		a := Parse(test.content)
+		if !reflect.DeepEqual(a.positional, test.positional) {
+			t.Errorf("%q: expected arguments %q, got %q", test.content, test.positional, a.positional)
+		}
+		if !reflect.DeepEqual(a.flags, test.flags) {
+			t.Errorf("%q: expected flags %v, got %v", test.content, test.flags, a.flags)
+		}
+		if key := errorKey(a.Err()); key != test.err {
+			t.Errorf("%q: expected error %q, got %q", test.content, test.err, key)
+		}
+	}
+}
+
+func TestKeyword(t *testing.T) {
+	tests := []struct {
+		content string
+		keyword string
+		found   bool
+		left    []string
+	}{
+		{"server rounds won group", "rounds won", true, []string{"server", "group"}},
+		{"server ROUNDS Won", "rounds won", true, []string{"server"}},
+		{"won rounds", "rounds won", false, []string{"won", "rounds"}},
+		{"server --rounds-won", "rounds won", true, []string{"server"}},
+		{"multi", "", false, []string{"multi"}},
+	}
+
+	for _, test := range tests {
+		a := Parse(test.content)
+		if found := a.Keyword(test.keyword); found != test.found {
+			t.Errorf("%q: expected keyword %q found to be %t", test.content, test.keyword, test.found)
+		}
+		if !reflect.DeepEqual(a.positional, test.left) {
+			t.Errorf("%q: expected %q left, got %q", test.content, test.left, a.positional)
+		}
+		if err := a.Done(); test.found && len(test.left) == 0 && err != nil {
+			t.Errorf("%q: the keyword was not marked as read: %s", test.content, errorKey(err))
+		}
+	}
+}
+
+func TestKeywordIn(t *testing.T) {
+	genders := map[string]string{"boy": "boy", "boys": "boy", "girl": "girl", "girls": "girl"}
+
+	tests := []struct {
+		content string
+		value   string
+		found   bool
+		err     string
+	}{
+		{"server Girls", "girl", true, "args.unknown-argument"},
+		{"boys", "boy", true, ""},
+		{"--girl", "girl", true, ""},
+		{"server", "", false, "args.unknown-argument"},
+		{"--girls --boy", "boy", true, "args.unknown-argument"},
+	}
+
+	for _, test := range tests {
+		a := Parse(test.content)
+		value, found := a.KeywordIn(genders)
+		if value != test.value || found != test.found {
+			t.Errorf("%q: expected %q %t, got %q %t", test.content, test.value, test.found, value, found)
+		}
+		if key := errorKey(a.Done()); key != test.err {
+			t.Errorf("%q: expected done error %q, got %q", test.content, test.err, key)
+		}
+	}
+}
+
+func TestKeywordValue(t *testing.T) {
+	tests := []struct {
+		content string
+		before  string
+		after   string
+		value   string
+		found   bool
+		left    []string
+	}{
+		{"server last 30 days group", "last", "days", "30", true, []string{"server", "group"}},
+		{"--last-days=7", "last", "days", "7", true, nil},
+		{"last 30 weeks", "last", "days", "", false, []string{"last", "30", "weeks"}},
+		{"from 2018-01-01 to 2018-01-31", "to", "", "2018-01-31", true, []string{"from", "2018-01-01"}},
+		{"--from=2018-01-01", "from", "", "2018-01-01", true, nil},
+		{"from", "from", "", "", false, []string{"from"}},
+	}
+
+	for _, test := range tests {
+		a := Parse(test.content)
+		value, found := a.KeywordValue(test.before, test.after)
+		if value != test.value || found != test.found {
+			t.Errorf("%q: expected %q %t, got %q %t", test.content, test.value, test.found, value, found)
+		}
+		if !reflect.DeepEqual(a.positional, test.left) {
+			t.Errorf("%q: expected %q left, got %q", test.content, test.left, a.positional)
+		}
+	}
+}
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		content string
+		number  int
+		err     string
+	}{
+		{"64", 64, ""},
+		{"32", 32, ""},
+		{"256", 256, ""},
+		{"16", 0, "args.number-out-of-range"},
+		{"512", 0, "args.number-out-of-range"},
+		{"many", 0, "args.not-a-number"},
+		{"", 0, "args.missing"},
+	}
+
+	for _, test := range tests {
+		a := Parse(test.content)
+		if number := a.Int("size", 32, 256); number != test.number {
+			t.Errorf("%q: expected %d, got %d", test.content, test.number, number)
+		}
+		if key := errorKey(a.Err()); key != test.err {
+			t.Errorf("%q: expected error %q, got %q", test.content, test.err, key)
+		}
+	}
+}
+
+func TestOptionalInt(t *testing.T) {
+	tests := []struct {
+		content string
+		number  int
+		err     string
+		left    int
+	}{
+		{"64", 64, "", 0},
+		{"", 32, "", 0},
+		{"girl", 32, "", 1},
+		{"512", 0, "args.number-out-of-range", 1},
+	}
+
+	for _, test := range tests {
+		a := Parse(test.content)
+		if number := a.OptionalInt("size", 32, 256, 32); number != test.number {
+			t.Errorf("%q: expected %d, got %d", test.content, test.number, number)
+		}
+		if key := errorKey(a.Err()); key != test.err {
+			t.Errorf("%q: expected error %q, got %q", test.content, test.err, key)
+		}
+		if a.Len() != test.left {
+			t.Errorf("%q: expected %d arguments left, got %d", test.content, test.left, a.Len())
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	a := Parse("<@123> <@!456> <#789> <@&321>")
+	if id := a.User("user"); id != "123" {
+		t.Errorf("expected user 123, got %q", id)
+	}
+	if id, ok := a.OptionalUser("user"); !ok || id != "456" {
+		t.Errorf("expected nickname mention of user 456, got %q", id)
+	}
+	if id, ok := a.OptionalRole("role"); ok {
+		t.Errorf("a channel mention was read as role %q", id)
+	}
+	if id := a.Channel("channel"); id != "789" {
+		t.Errorf("expected channel 789, got %q", id)
+	}
+	if id := a.Role("role"); id != "321" {
+		t.Errorf("expected role 321, got %q", id)
+	}
+	if err := a.Done(); err != nil {
+		t.Errorf("every mention was read but done failed: %s", errorKey(err))
+	}
+
+	a = Parse("nobody")
+	if id := a.User("user"); id != "" || errorKey(a.Err()) != "args.not-a-user" {
+		t.Errorf("expected a not a user error, got %q %q", id, errorKey(a.Err()))
+	}
+	if a.Len() != 1 {
+		t.Error("a failed mention should not be read")
+	}
+}
+
+func TestDone(t *testing.T) {
+	tests := []struct {
+		content string
+		read    func(a *Args)
+		err     string
+	}{
+		{"girl 64", func(a *Args) { a.String("gender"); a.Int("size", 32, 256) }, ""},
+		{"girl 64 extra", func(a *Args) { a.String("gender"); a.Int("size", 32, 256) }, "args.unknown-argument"},
+		{"girl --multi", func(a *Args) { a.String("gender") }, "args.unknown-argument"},
+		{"girl --multi", func(a *Args) { a.String("gender"); a.Flag("multi") }, ""},
+		{"girl 16 extra", func(a *Args) { a.String("gender"); a.Int("size", 32, 256) }, "args.number-out-of-range"},
+		{"group name with words", func(a *Args) { a.String("group"); a.Rest("name") }, ""},
+	}
+
+	for _, test := range tests {
+		a := Parse(test.content)
+		test.read(a)
+		if key := errorKey(a.Done()); key != test.err {
+			t.Errorf("%q: expected done error %q, got %q", test.content, test.err, key)
+		}
+	}
+}
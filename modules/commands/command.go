@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Handler is called when a command or sub command is used
+//  command - the command or sub command that triggered the handler
+//  content - the content after the command
+type Handler func(command string, content string, msg *discordgo.Message, session *discordgo.Session)
+
+// AccessLevel is who is allowed to use a command
+type AccessLevel int
+
+const (
+	AccessEveryone   AccessLevel = iota
+	AccessGuildAdmin             // users with manage server permission or one of the guilds admin roles
+	AccessBotOwner               // users listed as bot owners in the config
+)
+
+// Command is the declaration of a bot command. plugins declare their commands once and the dispatcher routes to them
+type Command struct {
+	// name used to call the command, must be lowercase
+	Name string
+
+	// alternative names the command can be called by
+	Aliases []string
+
+	// how the command should be used. ex: "biasgame [boy/girl/mixed] [32/64/128/256]"
+	Usage string
+
+	// i18n key of the description shown in the help command
+	Description string
+
+	// example uses of the command shown in the help command. ex: "biasgame boy 64"
+	Examples []string
+
+	// hides the command from the help command list. ex: commands only used in a specific channel
+	Hidden bool
+
+	// who is allowed to use the command. sub commands also require the access of their parent commands
+	Access AccessLevel
+
+	// discord permissions the user needs in the channel to use the command. ex: discordgo.PermissionManageMessages
+	Permissions int64
+
+	// commands that can follow this command. ex: "stats" for "!biasgame stats"
+	SubCommands []*Command
+
+	// called when this command is used and no sub command matched
+	Handler Handler
+
+	// map of sub command name/alias => sub command, built on registration
+	subCommandMap map[string]*Command
+
+	// command this is a sub command of, set on registration
+	parent *Command
+
+	// name of the plugin that registered the command, set on registration
+	plugin string
+}
+
+// Plugin returns the name of the plugin that registered the command
+func (c *Command) Plugin() string {
+	return c.plugin
+}
+
+// Parent returns the command this is a sub command of, or nil if this is a top level command
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// Path returns the full name of the command including its parent commands. ex: "biasgame stats"
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+
+	return c.parent.Path() + " " + c.Name
+}
+
+// Names returns the name of the command followed by all of its aliases
+func (c *Command) Names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+// Resolve will follow the sub commands of this command based on the content passed.
+//   returns the deepest matching command, the name it was called with, and the remaining content
+func (c *Command) Resolve(command string, content string) (*Command, string, string) {
+	resolvedCommand := c
+
+	for {
+		args := strings.SplitN(strings.TrimSpace(content), " ", 2)
+
+		subCommand, ok := resolvedCommand.subCommandMap[strings.ToLower(args[0])]
+		if !ok {
+			break
+		}
+
+		resolvedCommand = subCommand
+		command = strings.ToLower(args[0])
+		content = ""
+		if len(args) > 1 {
+			content = strings.TrimSpace(args[1])
+		}
+	}
+
+	return resolvedCommand, command, content
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Registry holds all registered commands so a command can be found with a single lookup
+type Registry struct {
+	// map of command name/alias => command
+	commands map[string]*Command
+
+	// names of the plugins that registered commands
+	plugins []string
+
+	mutex sync.RWMutex
+}
+
+// NewRegistry creates an empty command registry
+func NewRegistry() *Registry {
+	return &Registry{
+		commands: make(map[string]*Command),
+	}
+}
+
+// Register adds the given commands of the plugin and their sub commands to the registry.
+//   returns an error if a name or alias is already in use
+func (r *Registry) Register(pluginName string, commands ...*Command) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pluginName = strings.ToLower(pluginName)
+	for _, existingPlugin := range r.plugins {
+		if existingPlugin == pluginName {
+			return fmt.Errorf("plugin \"%s\" is already registered", pluginName)
+		}
+	}
+	r.plugins = append(r.plugins, pluginName)
+
+	for _, command := range commands {
+		if err := command.prepare(pluginName); err != nil {
+			return err
+		}
+
+		for _, name := range command.Names() {
+			name = strings.ToLower(name)
+
+			if _, ok := r.commands[name]; ok {
+				return fmt.Errorf("command \"%s\" is already registered", name)
+			}
+			r.commands[name] = command
+		}
+	}
+
+	return nil
+}
+
+// Find returns the command registered under the given name or alias
+func (r *Registry) Find(name string) (*Command, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	command, ok := r.commands[strings.ToLower(name)]
+	return command, ok
+}
+
+// Plugins returns the names of all plugins that registered commands, sorted by name
+func (r *Registry) Plugins() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	plugins := append([]string(nil), r.plugins...)
+	sort.Strings(plugins)
+
+	return plugins
+}
+
+// Commands returns every registered command once, sorted by name
+func (r *Registry) Commands() []*Command {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var allCommands []*Command
+	for name, command := range r.commands {
+		// aliases point to the same command, only add it under its main name
+		if name == command.Name {
+			allCommands = append(allCommands, command)
+		}
+	}
+
+	sort.Slice(allCommands, func(i, j int) bool {
+		return allCommands[i].Name < allCommands[j].Name
+	})
+
+	return allCommands
+}
+
+// prepare sets the plugin of the command and creates the name/alias lookup map for the commands sub commands
+func (c *Command) prepare(pluginName string) error {
+	if c.Name == "" {
+		return errors.New("command name can not be empty")
+	}
+
+	c.plugin = pluginName
+	c.subCommandMap = make(map[string]*Command)
+	for _, subCommand := range c.SubCommands {
+		subCommand.parent = c
+		if err := subCommand.prepare(pluginName); err != nil {
+			return err
+		}
+
+		for _, name := range subCommand.Names() {
+			name = strings.ToLower(name)
+
+			if _, ok := c.subCommandMap[name]; ok {
+				return fmt.Errorf("sub command \"%s %s\" is already registered", c.Name, name)
+			}
+			c.subCommandMap[name] = subCommand
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package modules
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	COOLDOWN_WARNING_DELETE_DELAY = time.Second * 5
+	COOLDOWN_CLEANUP_INTERVAL     = time.Minute * 10
+)
+
+var (
+	// buckets for every user/command, user, channel, and guild that has used a command
+	commandCooldowns = utils.NewRateLimiter()
+
+	// map of userID => time the user can be warned again. stops a spamming user from making the bot spam warnings
+	cooldownWarnings      = make(map[string]time.Time)
+	cooldownWarningsMutex sync.Mutex
+)
+
+// checkCooldowns takes a use of the command for the user, channel, and guild of the message.
+//   returns false and how long until the command can be used again if any of them are on cooldown,
+//   in which case no uses are taken from any of them
+//
+//   limits are set in the config, a missing limit means no limit:
+//     "cooldowns": {
+//       "user":     {"uses": 5, "seconds": 10},
+//       "channel":  {"uses": 15, "seconds": 10},
+//       "guild":    {"uses": 30, "seconds": 10},
+//       "commands": {"biasgame": {"uses": 1, "seconds": 10}}
+//     }
+func checkCooldowns(command *commands.Command, msg *discordgo.Message) (time.Duration, bool) {
+
+	// bot owners are never on cooldown
+	if utils.IsBotOwner(msg.Author.ID) {
+		return 0, true
+	}
+
+	cooldownConfig := cache.GetAppConfig().Cooldowns
+
+	// map of rate limiter bucket key => limit of that bucket
+	cooldowns := map[string]utils.RateLimit{
+		"command:" + command.Path() + ":" + msg.Author.ID: toRateLimit(cooldownConfig.Commands[command.Path()]),
+		"user:" + msg.Author.ID:                           toRateLimit(cooldownConfig.User),
+		"channel:" + msg.ChannelID:                        toRateLimit(cooldownConfig.Channel),
+	}
+
+	if guildID := utils.GetGuildIDFromMessage(msg); guildID != "" {
+		cooldowns["guild:"+guildID] = toRateLimit(cooldownConfig.Guild)
+	}
+
+	if allowed, retryAfter := commandCooldowns.AllowAll(cooldowns); !allowed {
+		return retryAfter, false
+	}
+
+	return 0, true
+}
+
+// sendCooldownWarning lets the user know to slow down. the warning is deleted after a short delay
+func sendCooldownWarning(msg *discordgo.Message, retryAfter time.Duration) {
+
+	// only warn once per cooldown
+	cooldownWarningsMutex.Lock()
+	if time.Now().Before(cooldownWarnings[msg.Author.ID]) {
+		cooldownWarningsMutex.Unlock()
+		return
+	}
+	cooldownWarnings[msg.Author.ID] = time.Now().Add(retryAfter)
+	cooldownWarningsMutex.Unlock()
+
+	warning, err := utils.SendMessagef(msg.ChannelID, "cooldowns.slow-down", msg.Author.Mention(), int(math.Ceil(retryAfter.Seconds())))
+	if err == nil {
+		go utils.DeleteImageWithDelay(warning, COOLDOWN_WARNING_DELETE_DELAY)
+	}
+}
+
+// cleanupCooldowns periodically removes cooldowns that have ended so they don't build up in memory
+func cleanupCooldowns() {
+	for {
+		time.Sleep(COOLDOWN_CLEANUP_INTERVAL)
+
+		commandCooldowns.RemoveStaleBuckets()
+
+		cooldownWarningsMutex.Lock()
+		for userID, warnUntil := range cooldownWarnings {
+			if time.Now().After(warnUntil) {
+				delete(cooldownWarnings, userID)
+			}
+		}
+		cooldownWarningsMutex.Unlock()
+	}
+}
+
+// toRateLimit converts a cooldown limit from the config to a rate limit
+func toRateLimit(limitConfig models.RateLimitConfig) utils.RateLimit {
+	return utils.RateLimit{
+		Uses:   limitConfig.Uses,
+		Period: time.Duration(limitConfig.Seconds * float64(time.Second)),
+	}
+}
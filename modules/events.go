@@ -0,0 +1,139 @@
+package modules
+
+/**
+ * Discord events for plugins.
+ * Plugins act on an event by implementing its handler interface, plugins that don't implement it are skipped
+ */
+
+import (
+	"fmt"
+
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// ReactionAddHandler is implemented by plugins that act on reactions added to messages
+type ReactionAddHandler interface {
+	ActionOnReactionAdd(reaction *discordgo.MessageReactionAdd)
+}
+
+// ReactionRemoveHandler is implemented by plugins that act on reactions removed from messages
+type ReactionRemoveHandler interface {
+	ActionOnReactionRemove(reaction *discordgo.MessageReactionRemove)
+}
+
+// MessageUpdateHandler is implemented by plugins that act on edited messages
+type MessageUpdateHandler interface {
+	ActionOnMessageUpdate(msg *discordgo.MessageUpdate)
+}
+
+// MessageDeleteHandler is implemented by plugins that act on deleted messages
+type MessageDeleteHandler interface {
+	ActionOnMessageDelete(msg *discordgo.MessageDelete)
+}
+
+// GuildMemberAddHandler is implemented by plugins that act on users joining a guild
+type GuildMemberAddHandler interface {
+	ActionOnGuildMemberAdd(member *discordgo.GuildMemberAdd)
+}
+
+// GuildCreateHandler is implemented by plugins that act on the bot joining a guild, or a guild becoming available
+type GuildCreateHandler interface {
+	ActionOnGuildCreate(guild *discordgo.GuildCreate)
+}
+
+// VoiceStateUpdateHandler is implemented by plugins that act on users joining, leaving, or muting in voice channels
+type VoiceStateUpdateHandler interface {
+	ActionOnVoiceStateUpdate(voiceState *discordgo.VoiceStateUpdate)
+}
+
+func CallBotPluginOnReactionAdd(reaction *discordgo.MessageReactionAdd) {
+	if isShuttingDown() {
+		return
+	}
+
+	reactionContext := fmt.Sprintf("Reaction: %s | User: %s | Message: %s | Channel: %s",
+		reaction.Emoji.Name, reaction.UserID, reaction.MessageID, reaction.ChannelID)
+
+	// check if the reaction was added to a paged message
+	if pagedMessage := utils.GetPagedMessage(reaction.MessageID); pagedMessage != nil {
+		func() {
+			defer utils.RecoverPanic("", "Paged message | "+reactionContext)
+
+			pagedMessage.UpdateMessagePage(reaction)
+		}()
+	}
+
+	callEachPlugin("ActionOnReactionAdd | "+reactionContext, func(plugin Plugin) {
+		if handler, ok := plugin.(ReactionAddHandler); ok {
+			handler.ActionOnReactionAdd(reaction)
+		}
+	})
+}
+
+func CallBotPluginOnReactionRemove(reaction *discordgo.MessageReactionRemove) {
+	reactionContext := fmt.Sprintf("Reaction: %s | User: %s | Message: %s | Channel: %s",
+		reaction.Emoji.Name, reaction.UserID, reaction.MessageID, reaction.ChannelID)
+
+	callEachPlugin("ActionOnReactionRemove | "+reactionContext, func(plugin Plugin) {
+		if handler, ok := plugin.(ReactionRemoveHandler); ok {
+			handler.ActionOnReactionRemove(reaction)
+		}
+	})
+}
+
+func CallBotPluginOnMessageUpdate(msg *discordgo.MessageUpdate) {
+	callEachPlugin(fmt.Sprintf("ActionOnMessageUpdate | Message: %s | Channel: %s", msg.ID, msg.ChannelID), func(plugin Plugin) {
+		if handler, ok := plugin.(MessageUpdateHandler); ok {
+			handler.ActionOnMessageUpdate(msg)
+		}
+	})
+}
+
+func CallBotPluginOnMessageDelete(msg *discordgo.MessageDelete) {
+	callEachPlugin(fmt.Sprintf("ActionOnMessageDelete | Message: %s | Channel: %s", msg.ID, msg.ChannelID), func(plugin Plugin) {
+		if handler, ok := plugin.(MessageDeleteHandler); ok {
+			handler.ActionOnMessageDelete(msg)
+		}
+	})
+}
+
+func CallBotPluginOnGuildMemberAdd(member *discordgo.GuildMemberAdd) {
+	callEachPlugin(fmt.Sprintf("ActionOnGuildMemberAdd | Guild: %s", member.GuildID), func(plugin Plugin) {
+		if handler, ok := plugin.(GuildMemberAddHandler); ok {
+			handler.ActionOnGuildMemberAdd(member)
+		}
+	})
+}
+
+func CallBotPluginOnGuildCreate(guild *discordgo.GuildCreate) {
+	callEachPlugin(fmt.Sprintf("ActionOnGuildCreate | Guild: %s", guild.ID), func(plugin Plugin) {
+		if handler, ok := plugin.(GuildCreateHandler); ok {
+			handler.ActionOnGuildCreate(guild)
+		}
+	})
+}
+
+func CallBotPluginOnVoiceStateUpdate(voiceState *discordgo.VoiceStateUpdate) {
+	callEachPlugin(fmt.Sprintf("ActionOnVoiceStateUpdate | User: %s | Guild: %s | Channel: %s", voiceState.UserID, voiceState.GuildID, voiceState.ChannelID), func(plugin Plugin) {
+		if handler, ok := plugin.(VoiceStateUpdateHandler); ok {
+			handler.ActionOnVoiceStateUpdate(voiceState)
+		}
+	})
+}
+
+// callEachPlugin runs the call for every plugin.
+//   each plugin is recovered separately so one failing plugin doesn't stop the others
+func callEachPlugin(context string, call func(plugin Plugin)) {
+	if isShuttingDown() {
+		return
+	}
+
+	for _, plugin := range pluginList {
+		func() {
+			defer utils.RecoverPanic("", fmt.Sprintf("%T | %s", plugin, context))
+
+			call(plugin)
+		}()
+	}
+}
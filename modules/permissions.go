@@ -0,0 +1,49 @@
+package modules
+
+import (
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// checkCommandAccess checks if the author of the message can use the command and all of its parent commands.
+//   returns the i18n key of the denial message if they can not
+func checkCommandAccess(command *commands.Command, msg *discordgo.Message) (string, bool) {
+
+	// bot owners can use every command
+	if utils.IsBotOwner(msg.Author.ID) {
+		return "", true
+	}
+
+	guildID := utils.GetGuildIDFromMessage(msg)
+	isGuildAdmin := guildID != "" && utils.IsGuildAdmin(msg.Author.ID, msg.ChannelID)
+
+	for checkCommand := command; checkCommand != nil; checkCommand = checkCommand.Parent() {
+
+		switch checkCommand.Access {
+		case commands.AccessBotOwner:
+			return "permissions.bot-owner-only", false
+		case commands.AccessGuildAdmin:
+			if !isGuildAdmin {
+				return "permissions.guild-admin-only", false
+			}
+		}
+
+		// discord permissions can only be checked in guilds
+		if checkCommand.Permissions != 0 {
+			if guildID == "" || !utils.UserHasPermissions(msg.Author.ID, msg.ChannelID, checkCommand.Permissions) {
+				return "permissions.missing-permissions", false
+			}
+		}
+
+		// guilds can limit commands to a list of roles. admins can always use them
+		if guildID != "" && !isGuildAdmin {
+			roleIDs := utils.GetGuildSettings(guildID).CommandRoleIDs[checkCommand.Path()]
+			if len(roleIDs) > 0 && !utils.UserHasAnyRole(guildID, msg.Author.ID, roleIDs) {
+				return "permissions.missing-role", false
+			}
+		}
+	}
+
+	return "", true
+}
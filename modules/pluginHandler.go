@@ -6,73 +6,191 @@ package modules
  */
 
 import (
+	"context"
+	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Snakeyesz/snek-bot/modules/plugins/biasgame"
 
 	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/modules/commands"
 	"github.com/Snakeyesz/snek-bot/modules/plugins"
+	"github.com/Snakeyesz/snek-bot/utils"
 	"github.com/bwmarrin/discordgo"
 )
 
 // Basic interface for plugins
 type Plugin interface {
+	// unique name of the plugin, used by guilds to enable or disable it
+	Name() string
+
 	// custom init for the plugins
 	//   golang init fires to soon in some cases
 	InitPlugin()
 
-	// Simple and efficient check if the passed command is valid
-	ValidateCommand(command string) bool
+	// Commands the plugin handles. called once when plugins are registered
+	Commands() []*commands.Command
+
+	// plugins can also act on discord events by implementing the handler interfaces in events.go
+}
 
-	// Entry point for plugig
-	// Action should also validate commands sent to it first to avoid running incorrectly
-	Action(
-		command string,
-		content string,
-		msg *discordgo.Message,
-		session *discordgo.Session,
-	)
+// ShutdownHandler is implemented by plugins that need to save or clean up their state before the bot exits
+type ShutdownHandler interface {
+	// should return once the plugin is done, or as soon as the context is done
+	Shutdown(ctx context.Context)
+}
 
-	ActionOnReactionAdd(reaction *discordgo.MessageReactionAdd)
+// ReloadHandler is implemented by plugins that need to act on the app config or i18n translations being reloaded
+type ReloadHandler interface {
+	ActionOnReload()
 }
 
+const (
+	RESTRICTION_MESSAGE_DELETE_DELAY = time.Second * 10
+)
+
 // List of active plugins
 var pluginList []Plugin
 
-func InitPlugins() {
+// all commands declared by the active plugins
+var commandRegistry *commands.Registry
+
+// set once shutdown starts so plugins stop receiving commands and events
+var pluginsShuttingDown int32
+
+// reloadConfigs is used by the reload command to reload the config and i18n files
+func InitPlugins(reloadConfigs func() error) {
+	commandRegistry = commands.NewRegistry()
+
 	pluginList = []Plugin{
 		&plugins.Cat{},
 		&plugins.Pong{},
 		&plugins.Music{},
+		&plugins.Help{Registry: commandRegistry},
+		&plugins.Settings{Registry: commandRegistry},
+		&plugins.Reload{ReloadConfigs: reloadConfigs},
+		&plugins.Status{},
 		&biasgame.BiasGame{},
 	}
 
+	// register plugin commands before init so commands are routable right away
 	for _, plugin := range pluginList {
-		go plugin.InitPlugin()
+		err := commandRegistry.Register(plugin.Name(), plugin.Commands()...)
+		utils.PanicCheck(err)
 	}
+
+	for _, plugin := range pluginList {
+		go func(plugin Plugin) {
+			defer utils.RecoverPanic("", fmt.Sprintf("InitPlugin of %T", plugin))
+
+			plugin.InitPlugin()
+		}(plugin)
+	}
+
+	go cleanupCooldowns()
+}
+
+// ShutdownPlugins stops plugins from receiving commands and events, then gives each plugin a chance to save its state.
+//   returns the context error if the plugins didn't finish in time
+func ShutdownPlugins(ctx context.Context) error {
+	atomic.StoreInt32(&pluginsShuttingDown, 1)
+
+	var wg sync.WaitGroup
+	for _, plugin := range pluginList {
+		handler, ok := plugin.(ShutdownHandler)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(plugin Plugin, handler ShutdownHandler) {
+			defer wg.Done()
+			defer utils.RecoverPanic("", fmt.Sprintf("Shutdown of %T", plugin))
+
+			handler.Shutdown(ctx)
+		}(plugin, handler)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReloadPlugins tells plugins the app config and i18n translations were reloaded
+func ReloadPlugins() {
+	callEachPlugin("ActionOnReload", func(plugin Plugin) {
+		if handler, ok := plugin.(ReloadHandler); ok {
+			handler.ActionOnReload()
+		}
+	})
+}
+
+// isShuttingDown returns true once ShutdownPlugins has been called
+func isShuttingDown() bool {
+	return atomic.LoadInt32(&pluginsShuttingDown) == 1
 }
 
 // command - The command that triggered this execution
 // content - The content without command
 // msg     - The message object
 func CallBotPlugin(command string, content string, msg *discordgo.Message) {
+	if isShuttingDown() {
+		return
+	}
+
+	// a panic in a plugin should never crash the bot
+	defer utils.RecoverPanic(msg.ChannelID, fmt.Sprintf("Command: %s | Content: %s | User: %s (%s) | Channel: %s",
+		command, content, msg.Author.Username, msg.Author.ID, msg.ChannelID))
+
 	// Convert to command to lowercase
 	command = strings.ToLower(command)
 
-	// Run plugins for the given command
-	for _, plugin := range pluginList {
+	// find the command the plugins registered
+	registeredCommand, ok := commandRegistry.Find(command)
+	if !ok {
+		return
+	}
 
-		if plugin.ValidateCommand(command) {
-			plugin.Action(command, content, msg, cache.GetDiscordSession())
-		}
+	// follow any sub commands in the content
+	registeredCommand, command, content = registeredCommand.Resolve(command, content)
+	if registeredCommand.Handler == nil {
+		return
 	}
-}
 
-func CallBotPluginOnReactionAdd(reaction *discordgo.MessageReactionAdd) {
+	// make sure the guild allows the command in this channel
+	if denialMessage, ok := checkGuildRestrictions(registeredCommand, msg); !ok {
+		if denialMessage != "" {
+			sentMessage, err := utils.SendMessage(msg.ChannelID, denialMessage)
+			if err == nil {
+				go utils.DeleteImageWithDelay(sentMessage, RESTRICTION_MESSAGE_DELETE_DELAY)
+			}
+		}
+		return
+	}
 
-	/// Run plugins for the given command
-	for _, plugin := range pluginList {
+	// make sure the user is allowed to use the command
+	if denialMessage, ok := checkCommandAccess(registeredCommand, msg); !ok {
+		utils.SendMessage(msg.ChannelID, denialMessage)
+		return
+	}
 
-		plugin.ActionOnReactionAdd(reaction)
+	// make sure the user, channel, or guild isn't using commands too quickly
+	if retryAfter, ok := checkCooldowns(registeredCommand, msg); !ok {
+		sendCooldownWarning(msg, retryAfter)
+		return
 	}
+
+	registeredCommand.Handler(command, content, msg, cache.GetDiscordSession())
 }
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"github.com/Snakeyesz/snek-bot/modules/commands"
 	"github.com/Snakeyesz/snek-bot/utils"
 	"github.com/bwmarrin/discordgo"
 )
@@ -8,19 +9,23 @@ import (
 // Plugin responds to command by displaying a image retrieved from http://random.cat/meow
 type Cat struct{}
 
-func (c *Cat) InitPlugin() {}
+func (c *Cat) Name() string {
+	return "cat"
+}
 
-// Will validate if the pass command entered is used for this plugin
-func (c *Cat) ValidateCommand(command string) bool {
-	validCommands := []string{"meow", "cat", "randomcat"}
+func (c *Cat) InitPlugin() {}
 
-	for _, v := range validCommands {
-		if v == command {
-			return true
-		}
+// Commands handled by this plugin
+func (c *Cat) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "cat",
+			Aliases:     []string{"meow", "randomcat"},
+			Usage:       "cat",
+			Description: "help.descriptions.cat",
+			Handler:     c.Action,
+		},
 	}
-
-	return false
 }
 
 // Main Entry point for the plugin
@@ -42,7 +47,3 @@ func (c *Cat) Action(command string, content string, msg *discordgo.Message, ses
 		)
 	}
 }
-
-func (c *Cat) ActionOnReactionAdd(reaction *discordgo.MessageReactionAdd) {
-
-}
@@ -0,0 +1,139 @@
+package plugins
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Plugin displays the available commands and how to use them, built from the commands the plugins registered
+type Help struct {
+	Registry *commands.Registry
+}
+
+func (h *Help) Name() string {
+	return "help"
+}
+
+func (h *Help) InitPlugin() {}
+
+// Commands handled by this plugin
+func (h *Help) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "help",
+			Aliases:     []string{"commands"},
+			Usage:       "help [command]",
+			Description: "help.descriptions.help",
+			Examples:    []string{"help", "help biasgame", "help biasgame stats"},
+			Handler:     h.Action,
+		},
+	}
+}
+
+// Main Entry point for the plugin
+func (h *Help) Action(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	commandArgs := strings.Fields(content)
+	prefix := utils.GetGuildPrefix(utils.GetGuildIDFromMessage(msg))
+
+	// no command was given, list all commands
+	if len(commandArgs) == 0 {
+		h.sendCommandList(msg, prefix)
+		return
+	}
+
+	// find the command and any sub command the user wants help with
+	helpCommand, ok := h.Registry.Find(commandArgs[0])
+	if !ok || helpCommand.Hidden {
+		utils.SendMessagef(msg.ChannelID, "help.unknown-command", commandArgs[0])
+		return
+	}
+	helpCommand, _, _ = helpCommand.Resolve(commandArgs[0], strings.Join(commandArgs[1:], " "))
+
+	h.sendCommandDetails(msg, helpCommand, prefix)
+}
+
+// sendCommandList sends a paged message of every command and its description
+func (h *Help) sendCommandList(msg *discordgo.Message, prefix string) {
+	embed := &discordgo.MessageEmbed{
+		Color: 0x0FADED, // blueish
+		Author: &discordgo.MessageEmbedAuthor{
+			Name: utils.Geti18nText("help.list-title"),
+		},
+		Description: utils.Geti18nTextF("help.list-description", prefix),
+	}
+
+	for _, command := range h.Registry.Commands() {
+		if command.Hidden {
+			continue
+		}
+
+		fieldName := prefix + command.Name
+		if len(command.Aliases) > 0 {
+			fieldName += fmt.Sprintf(" (%s)", strings.Join(command.Aliases, ", "))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fieldName,
+			Value:  getCommandDescription(command),
+			Inline: false,
+		})
+	}
+
+	utils.SendPagedMessage(msg, embed, 10)
+}
+
+// sendCommandDetails sends a paged message with the usage, examples, and sub commands of a command
+func (h *Help) sendCommandDetails(msg *discordgo.Message, command *commands.Command, prefix string) {
+	embed := &discordgo.MessageEmbed{
+		Color: 0x0FADED, // blueish
+		Author: &discordgo.MessageEmbedAuthor{
+			Name: prefix + command.Usage,
+		},
+		Description: getCommandDescription(command),
+	}
+
+	if len(command.Aliases) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   utils.Geti18nText("help.aliases"),
+			Value:  strings.Join(command.Aliases, ", "),
+			Inline: false,
+		})
+	}
+
+	if len(command.Examples) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   utils.Geti18nText("help.examples"),
+			Value:  fmt.Sprintf("```%s%s```", prefix, strings.Join(command.Examples, "\n"+prefix)),
+			Inline: false,
+		})
+	}
+
+	// each sub command gets its own field so they can be paged through
+	for _, subCommand := range command.SubCommands {
+		if subCommand.Hidden {
+			continue
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   prefix + subCommand.Usage,
+			Value:  getCommandDescription(subCommand),
+			Inline: false,
+		})
+	}
+
+	utils.SendPagedMessage(msg, embed, 8)
+}
+
+// getCommandDescription returns the translated description of the command
+//   discord embeds can't have empty field values so a default is given if the command has none
+func getCommandDescription(command *commands.Command) string {
+	if command.Description == "" {
+		return utils.Geti18nText("help.no-description")
+	}
+
+	return utils.Geti18nText(command.Description)
+}
@@ -1,8 +1,11 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/modules/commands/args"
 	"github.com/Snakeyesz/snek-bot/modules/plugins/voice"
 
 	"github.com/bwmarrin/discordgo"
@@ -11,27 +14,34 @@ import (
 // Plugin joins voice chat of the user that initiated it and plays music based on the passed link
 type Music struct{}
 
+func (p *Music) Name() string {
+	return "music"
+}
+
 func (p *Music) InitPlugin() {}
 
-// will validate if the pass command is used for this plugin
-func (p *Music) ValidateCommand(command string) bool {
-	validCommands := []string{
-		"play",
-		"stop",
-		"skip",
-		"pause",
-		"unpause",
-		"repeat",
-		"shuffle",
-	}
+// Shutdown stops all music and leaves the voice channels before the bot exits
+func (p *Music) Shutdown(ctx context.Context) {
+	voice.StopAllMusic(ctx)
+}
 
-	for _, v := range validCommands {
-		if v == command {
-			return true
-		}
+// Commands handled by this plugin
+func (p *Music) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "play",
+			Usage:       "play [video url]",
+			Description: "help.descriptions.play",
+			Examples:    []string{"play https://www.youtube.com/watch?v=9bZkp7q19f0", "play"},
+			Handler:     p.Action,
+		},
+		{Name: "stop", Usage: "stop", Description: "help.descriptions.stop", Handler: p.Action},
+		{Name: "skip", Usage: "skip", Description: "help.descriptions.skip", Handler: p.Action},
+		{Name: "pause", Usage: "pause", Description: "help.descriptions.pause", Handler: p.Action},
+		{Name: "unpause", Usage: "unpause", Description: "help.descriptions.unpause", Handler: p.Action},
+		{Name: "repeat", Usage: "repeat", Description: "help.descriptions.repeat", Handler: p.Action},
+		{Name: "shuffle", Usage: "shuffle", Handler: p.Action, Hidden: true}, // not implemented yet
 	}
-
-	return false
 }
 
 // Main Entry point for the plugin
@@ -48,14 +58,20 @@ func (p *Music) Action(command string, content string, msg *discordgo.Message, s
 
 	switch command {
 	case "play":
+		commandArgs := args.Parse(content)
+		url := commandArgs.OptionalString("")
+		if err := commandArgs.Done(); err != nil {
+			args.SendError(msg, err, "play")
+			return
+		}
 
-		// if content length is nothing, assume they're playing from a pause
-		if len(content) == 0 {
+		// if no url was given, assume they're playing from a pause
+		if url == "" {
 
 			voiceInstance.TogglePauseSong(false)
 
 		} else {
-			voiceInstance.PlaySongByUrl(content, msg)
+			voiceInstance.PlaySongByUrl(url, msg)
 		}
 	case "stop":
 		voiceInstance.StopMusic()
@@ -71,7 +87,3 @@ func (p *Music) Action(command string, content string, msg *discordgo.Message, s
 
 	}
 }
-
-func (m *Music) ActionOnReactionAdd(reaction *discordgo.MessageReactionAdd) {
-
-}
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"github.com/Snakeyesz/snek-bot/modules/commands"
 	"github.com/Snakeyesz/snek-bot/utils"
 	"github.com/bwmarrin/discordgo"
 )
@@ -8,19 +9,28 @@ import (
 // plugin will simply respond to "ping" with "pong" and vica versa
 type Pong struct{}
 
-func (p *Pong) InitPlugin() {}
+func (p *Pong) Name() string {
+	return "pong"
+}
 
-// will validate if the pass command is used for this plugin
-func (p *Pong) ValidateCommand(command string) bool {
-	validCommands := []string{"ping", "pong"}
+func (p *Pong) InitPlugin() {}
 
-	for _, v := range validCommands {
-		if v == command {
-			return true
-		}
+// Commands handled by this plugin
+func (p *Pong) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "ping",
+			Usage:       "ping",
+			Description: "help.descriptions.ping",
+			Handler:     p.Action,
+		},
+		{
+			Name:        "pong",
+			Usage:       "pong",
+			Description: "help.descriptions.pong",
+			Handler:     p.Action,
+		},
 	}
-
-	return false
 }
 
 // Main entry point for plugin
@@ -36,7 +46,3 @@ func (p *Pong) Action(command string, content string, msg *discordgo.Message, se
 		utils.SendMessage(msg.ChannelID, "Ping!")
 	}
 }
-
-func (p *Pong) ActionOnReactionAdd(reaction *discordgo.MessageReactionAdd) {
-
-}
@@ -0,0 +1,42 @@
+package plugins
+
+import (
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Plugin lets bot owners reload the config and i18n files without restarting the bot
+type Reload struct {
+	ReloadConfigs func() error
+}
+
+func (r *Reload) Name() string {
+	return "reload"
+}
+
+func (r *Reload) InitPlugin() {}
+
+// Commands handled by this plugin
+func (r *Reload) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "reload",
+			Usage:       "reload",
+			Description: "help.descriptions.reload",
+			Access:      commands.AccessBotOwner,
+			Handler:     r.Action,
+		},
+	}
+}
+
+// Main entry point for plugin
+func (r *Reload) Action(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	err := r.ReloadConfigs()
+	if err != nil {
+		utils.SendMessagef(msg.ChannelID, "reload.failed", err.Error())
+		return
+	}
+
+	utils.SendMessage(msg.ChannelID, "reload.done")
+}
@@ -0,0 +1,446 @@
+package plugins
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	SETTINGS_PLUGIN_NAME = "settings"
+)
+
+// Plugin lets server admins change how the bot behaves in their server
+type Settings struct {
+	Registry *commands.Registry
+}
+
+func (s *Settings) Name() string {
+	return SETTINGS_PLUGIN_NAME
+}
+
+func (s *Settings) InitPlugin() {}
+
+// Commands handled by this plugin
+func (s *Settings) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "prefix",
+			Usage:       "prefix",
+			Description: "help.descriptions.prefix",
+			Handler:     whenInGuild(s.showPrefix),
+			SubCommands: []*commands.Command{
+				{
+					Name:        "set",
+					Usage:       "prefix set [new prefix]",
+					Description: "help.descriptions.prefix-set",
+					Examples:    []string{"prefix set ?", "prefix set snek!"},
+					Access:      commands.AccessGuildAdmin,
+					Handler:     whenInGuild(s.setPrefix),
+				},
+				{
+					Name:        "reset",
+					Usage:       "prefix reset",
+					Description: "help.descriptions.prefix-reset",
+					Access:      commands.AccessGuildAdmin,
+					Handler:     whenInGuild(s.resetPrefix),
+				},
+			},
+		},
+		{
+			Name:        "timezone",
+			Usage:       "timezone",
+			Description: "help.descriptions.timezone",
+			Handler:     whenInGuild(s.showTimezone),
+			SubCommands: []*commands.Command{
+				{
+					Name:        "set",
+					Usage:       "timezone set [time zone name]",
+					Description: "help.descriptions.timezone-set",
+					Examples:    []string{"timezone set America/New_York", "timezone set Asia/Seoul"},
+					Access:      commands.AccessGuildAdmin,
+					Handler:     whenInGuild(s.setTimezone),
+				},
+				{
+					Name:        "reset",
+					Usage:       "timezone reset",
+					Description: "help.descriptions.timezone-reset",
+					Access:      commands.AccessGuildAdmin,
+					Handler:     whenInGuild(s.resetTimezone),
+				},
+			},
+		},
+		{
+			Name:        "adminroles",
+			Usage:       "adminroles",
+			Description: "help.descriptions.adminroles",
+			Access:      commands.AccessGuildAdmin,
+			Handler:     whenInGuild(s.showAdminRoles),
+			SubCommands: []*commands.Command{
+				{
+					Name:        "add",
+					Usage:       "adminroles add [@role...]",
+					Description: "help.descriptions.adminroles-add",
+					Examples:    []string{"adminroles add @Moderators"},
+					Handler:     whenInGuild(s.addAdminRoles),
+				},
+				{
+					Name:        "remove",
+					Usage:       "adminroles remove [@role...]",
+					Description: "help.descriptions.adminroles-remove",
+					Handler:     whenInGuild(s.removeAdminRoles),
+				},
+			},
+		},
+		{
+			Name:        "commandroles",
+			Usage:       "commandroles",
+			Description: "help.descriptions.commandroles",
+			Access:      commands.AccessGuildAdmin,
+			Handler:     whenInGuild(s.showCommandRoles),
+			SubCommands: []*commands.Command{
+				{
+					Name:        "set",
+					Usage:       "commandroles set [command] [@role...]",
+					Description: "help.descriptions.commandroles-set",
+					Examples:    []string{"commandroles set biasgame multi @Gamers", "commandroles set play @DJ @Moderators"},
+					Handler:     whenInGuild(s.setCommandRoles),
+				},
+				{
+					Name:        "clear",
+					Usage:       "commandroles clear [command]",
+					Description: "help.descriptions.commandroles-clear",
+					Examples:    []string{"commandroles clear play"},
+					Handler:     whenInGuild(s.clearCommandRoles),
+				},
+			},
+		},
+		{
+			Name:        "plugins",
+			Usage:       "plugins",
+			Description: "help.descriptions.plugins",
+			Access:      commands.AccessGuildAdmin,
+			Handler:     whenInGuild(s.listPlugins),
+		},
+		{
+			Name:        "enable",
+			Usage:       "enable [plugin/command] [name]",
+			Description: "help.descriptions.enable",
+			Examples:    []string{"enable plugin music", "enable command biasgame multi"},
+			Access:      commands.AccessGuildAdmin,
+			Handler:     whenInGuild(s.enablePluginOrCommand),
+		},
+		{
+			Name:        "disable",
+			Usage:       "disable [plugin/command] [name]",
+			Description: "help.descriptions.disable",
+			Examples:    []string{"disable plugin music", "disable command biasgame multi"},
+			Access:      commands.AccessGuildAdmin,
+			Handler:     whenInGuild(s.disablePluginOrCommand),
+		},
+		{
+			Name:        "channels",
+			Usage:       "channels",
+			Description: "help.descriptions.channels",
+			Access:      commands.AccessGuildAdmin,
+			Handler:     whenInGuild(s.listChannelRestrictions),
+			SubCommands: []*commands.Command{
+				{
+					Name:        "allow",
+					Usage:       "channels allow [plugin/command] [name] [#channel...]",
+					Description: "help.descriptions.channels-allow",
+					Examples:    []string{"channels allow command biasgame #games", "channels allow plugin music #music #bot-spam"},
+					Handler:     whenInGuild(s.allowInChannels),
+				},
+				{
+					Name:        "deny",
+					Usage:       "channels deny [plugin/command] [name] [#channel...]",
+					Description: "help.descriptions.channels-deny",
+					Examples:    []string{"channels deny plugin cat #general"},
+					Handler:     whenInGuild(s.denyInChannels),
+				},
+				{
+					Name:        "clear",
+					Usage:       "channels clear [plugin/command] [name]",
+					Description: "help.descriptions.channels-clear",
+					Examples:    []string{"channels clear command biasgame"},
+					Handler:     whenInGuild(s.clearChannelRestrictions),
+				},
+			},
+		},
+	}
+}
+
+/////////////////////////////////
+//       PREFIX COMMANDS       //
+/////////////////////////////////
+
+// showPrefix displays the current prefix of the guild
+func (s *Settings) showPrefix(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	utils.SendMessagef(msg.ChannelID, "settings.prefix.current", utils.GetGuildPrefix(utils.GetGuildIDFromMessage(msg)))
+}
+
+// setPrefix changes the prefix of the guild
+func (s *Settings) setPrefix(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	newPrefix := strings.TrimSpace(content)
+
+	// prefixes can't have spaces since commands are split on them
+	if newPrefix == "" || len(newPrefix) > utils.MAX_PREFIX_LENGTH || strings.ContainsAny(newPrefix, " \n\t") {
+		utils.SendMessagef(msg.ChannelID, "settings.prefix.invalid", utils.MAX_PREFIX_LENGTH)
+		return
+	}
+
+	s.savePrefix(msg, newPrefix)
+}
+
+// resetPrefix changes the prefix of the guild back to the default
+func (s *Settings) resetPrefix(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	s.savePrefix(msg, "")
+}
+
+// savePrefix saves the prefix to the guilds settings and lets the user know what the prefix now is
+func (s *Settings) savePrefix(msg *discordgo.Message, newPrefix string) {
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	settings.Prefix = newPrefix
+
+	if !saveSettings(msg, settings) {
+		return
+	}
+
+	utils.SendMessagef(msg.ChannelID, "settings.prefix.updated", utils.GetGuildPrefix(settings.GuildID))
+}
+
+/////////////////////////////////
+//      TIMEZONE COMMANDS      //
+/////////////////////////////////
+
+// showTimezone displays the time zone of the guild
+func (s *Settings) showTimezone(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	utils.SendMessagef(msg.ChannelID, "settings.timezone.current", utils.GetGuildLocation(utils.GetGuildIDFromMessage(msg)).String())
+}
+
+// setTimezone changes the time zone of the guild
+func (s *Settings) setTimezone(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	newTimezone := strings.TrimSpace(content)
+
+	// only names in the time zone database are allowed, "Local" would be the time zone of the bot
+	if _, err := time.LoadLocation(newTimezone); err != nil || newTimezone == "" || newTimezone == "Local" {
+		utils.SendMessage(msg.ChannelID, "settings.timezone.invalid")
+		return
+	}
+
+	s.saveTimezone(msg, newTimezone)
+}
+
+// resetTimezone changes the time zone of the guild back to utc
+func (s *Settings) resetTimezone(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	s.saveTimezone(msg, "")
+}
+
+// saveTimezone saves the time zone to the guilds settings and lets the user know what the time zone now is
+func (s *Settings) saveTimezone(msg *discordgo.Message, newTimezone string) {
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	settings.Timezone = newTimezone
+
+	if !saveSettings(msg, settings) {
+		return
+	}
+
+	utils.SendMessagef(msg.ChannelID, "settings.timezone.updated", utils.GetGuildLocation(settings.GuildID).String())
+}
+
+/////////////////////////////////
+//     ADMIN ROLE COMMANDS     //
+/////////////////////////////////
+
+// showAdminRoles displays the roles that can use admin commands
+func (s *Settings) showAdminRoles(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+
+	if len(settings.AdminRoleIDs) == 0 {
+		utils.SendMessage(msg.ChannelID, "settings.admin-roles.none")
+		return
+	}
+
+	utils.SendMessagef(msg.ChannelID, "settings.admin-roles.current", mentionRoles(settings.AdminRoleIDs))
+}
+
+// addAdminRoles adds the mentioned roles to the guilds admin roles
+func (s *Settings) addAdminRoles(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	if len(msg.MentionRoles) == 0 {
+		utils.SendMessage(msg.ChannelID, "settings.no-roles-mentioned")
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	for _, roleID := range msg.MentionRoles {
+		if !utils.ContainsString(settings.AdminRoleIDs, roleID) {
+			settings.AdminRoleIDs = append(settings.AdminRoleIDs, roleID)
+		}
+	}
+
+	if saveSettings(msg, settings) {
+		utils.SendMessagef(msg.ChannelID, "settings.admin-roles.current", mentionRoles(settings.AdminRoleIDs))
+	}
+}
+
+// removeAdminRoles removes the mentioned roles from the guilds admin roles
+func (s *Settings) removeAdminRoles(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	if len(msg.MentionRoles) == 0 {
+		utils.SendMessage(msg.ChannelID, "settings.no-roles-mentioned")
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	settings.AdminRoleIDs = utils.RemoveStrings(settings.AdminRoleIDs, msg.MentionRoles)
+
+	if !saveSettings(msg, settings) {
+		return
+	}
+
+	if len(settings.AdminRoleIDs) == 0 {
+		utils.SendMessage(msg.ChannelID, "settings.admin-roles.none")
+	} else {
+		utils.SendMessagef(msg.ChannelID, "settings.admin-roles.current", mentionRoles(settings.AdminRoleIDs))
+	}
+}
+
+/////////////////////////////////
+//    COMMAND ROLE COMMANDS    //
+/////////////////////////////////
+
+// showCommandRoles lists the commands limited to specific roles
+func (s *Settings) showCommandRoles(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+
+	if len(settings.CommandRoleIDs) == 0 {
+		utils.SendMessage(msg.ChannelID, "settings.command-roles.none")
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color: 0x0FADED, // blueish
+		Author: &discordgo.MessageEmbedAuthor{
+			Name: utils.Geti18nText("settings.command-roles.title"),
+		},
+	}
+	for commandPath, roleIDs := range settings.CommandRoleIDs {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   commandPath,
+			Value:  mentionRoles(roleIDs),
+			Inline: false,
+		})
+	}
+
+	// sort fields by command
+	sort.Slice(embed.Fields, func(i, j int) bool {
+		return embed.Fields[i].Name < embed.Fields[j].Name
+	})
+
+	utils.SendPagedMessage(msg, embed, 10)
+}
+
+// setCommandRoles limits a command to the mentioned roles
+func (s *Settings) setCommandRoles(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	if len(msg.MentionRoles) == 0 {
+		utils.SendMessage(msg.ChannelID, "settings.no-roles-mentioned")
+		return
+	}
+
+	commandPath, ok := s.findCommandPath(msg, content)
+	if !ok {
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	if settings.CommandRoleIDs == nil {
+		settings.CommandRoleIDs = make(map[string][]string)
+	}
+	settings.CommandRoleIDs[commandPath] = msg.MentionRoles
+
+	if saveSettings(msg, settings) {
+		utils.SendMessagef(msg.ChannelID, "settings.command-roles.updated", commandPath, mentionRoles(msg.MentionRoles))
+	}
+}
+
+// clearCommandRoles lets everyone use a command that was limited to specific roles
+func (s *Settings) clearCommandRoles(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	commandPath, ok := s.findCommandPath(msg, content)
+	if !ok {
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	delete(settings.CommandRoleIDs, commandPath)
+
+	if saveSettings(msg, settings) {
+		utils.SendMessagef(msg.ChannelID, "settings.command-roles.cleared", commandPath)
+	}
+}
+
+// findCommandPath finds the registered command named in the content, ignoring any mentions.
+//   will let the user know if the command does not exist
+func (s *Settings) findCommandPath(msg *discordgo.Message, content string) (string, bool) {
+	var commandArgs []string
+	for _, arg := range strings.Fields(content) {
+		if !strings.HasPrefix(arg, "<@") {
+			commandArgs = append(commandArgs, strings.ToLower(arg))
+		}
+	}
+
+	if len(commandArgs) > 0 {
+		if registeredCommand, ok := s.Registry.Find(commandArgs[0]); ok {
+			registeredCommand, _, remainingContent := registeredCommand.Resolve(commandArgs[0], strings.Join(commandArgs[1:], " "))
+
+			// every word must be part of the command
+			if remainingContent == "" {
+				return registeredCommand.Path(), true
+			}
+		}
+	}
+
+	utils.SendMessagef(msg.ChannelID, "help.unknown-command", strings.Join(commandArgs, " "))
+	return "", false
+}
+
+/////////////////////////////////
+//          HELPERS            //
+/////////////////////////////////
+
+// saveSettings saves the guild settings and lets the user know if it failed
+func saveSettings(msg *discordgo.Message, settings models.GuildSettingsEntry) bool {
+	err := utils.SaveGuildSettings(settings)
+	if err != nil {
+		utils.SendMessage(msg.ChannelID, "settings.save-failed")
+		return false
+	}
+
+	return true
+}
+
+// mentionRoles returns the roles as a comma delimited list of role mentions
+func mentionRoles(roleIDs []string) string {
+	var mentions []string
+	for _, roleID := range roleIDs {
+		mentions = append(mentions, "<@&"+roleID+">")
+	}
+
+	return strings.Join(mentions, ", ")
+}
+
+// whenInGuild wraps a command handler so it will only run for messages sent in a guild
+func whenInGuild(handler commands.Handler) commands.Handler {
+	return func(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+		if utils.GetGuildIDFromMessage(msg) == "" {
+			utils.SendMessage(msg.ChannelID, "settings.guild-only")
+			return
+		}
+
+		handler(command, content, msg, session)
+	}
+}
@@ -0,0 +1,259 @@
+package plugins
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+var channelMentionRegex = regexp.MustCompile(`<#(\d+)>`)
+
+/////////////////////////////////
+//   PLUGIN/COMMAND TOGGLES    //
+/////////////////////////////////
+
+// listPlugins lists all plugins and commands and if they are enabled in the guild
+func (s *Settings) listPlugins(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+
+	var pluginStatuses []string
+	for _, pluginName := range s.Registry.Plugins() {
+		status := utils.Geti18nText("restrictions.enabled")
+		if utils.ContainsString(settings.DisabledPlugins, pluginName) {
+			status = utils.Geti18nText("restrictions.disabled")
+		}
+
+		pluginStatuses = append(pluginStatuses, fmt.Sprintf("**%s** - %s", pluginName, status))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color: 0x0FADED, // blueish
+		Author: &discordgo.MessageEmbedAuthor{
+			Name: utils.Geti18nText("restrictions.plugins-title"),
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   utils.Geti18nText("restrictions.plugins"),
+				Value:  strings.Join(pluginStatuses, "\n"),
+				Inline: false,
+			},
+		},
+	}
+
+	if len(settings.DisabledCommands) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   utils.Geti18nText("restrictions.disabled-commands"),
+			Value:  strings.Join(settings.DisabledCommands, "\n"),
+			Inline: false,
+		})
+	}
+
+	utils.SendEmbed(msg.ChannelID, embed)
+}
+
+// enablePluginOrCommand enables a plugin or command that was disabled in the guild
+func (s *Settings) enablePluginOrCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	targetType, targetName, ok := s.findRestrictionTarget(msg, content)
+	if !ok {
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	if targetType == "plugin" {
+		settings.DisabledPlugins = utils.RemoveStrings(settings.DisabledPlugins, []string{targetName})
+	} else {
+		settings.DisabledCommands = utils.RemoveStrings(settings.DisabledCommands, []string{targetName})
+	}
+
+	if saveSettings(msg, settings) {
+		utils.SendMessagef(msg.ChannelID, "restrictions.target-enabled", targetType, targetName)
+	}
+}
+
+// disablePluginOrCommand disables a plugin or command in the guild
+func (s *Settings) disablePluginOrCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	targetType, targetName, ok := s.findRestrictionTarget(msg, content)
+	if !ok {
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	if targetType == "plugin" {
+		if !utils.ContainsString(settings.DisabledPlugins, targetName) {
+			settings.DisabledPlugins = append(settings.DisabledPlugins, targetName)
+		}
+	} else {
+		if !utils.ContainsString(settings.DisabledCommands, targetName) {
+			settings.DisabledCommands = append(settings.DisabledCommands, targetName)
+		}
+	}
+
+	if saveSettings(msg, settings) {
+		utils.SendMessagef(msg.ChannelID, "restrictions.target-disabled", targetType, targetName)
+	}
+}
+
+/////////////////////////////////
+//    CHANNEL RESTRICTIONS     //
+/////////////////////////////////
+
+// listChannelRestrictions lists the channels plugins and commands are allowed or denied in
+func (s *Settings) listChannelRestrictions(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+
+	if len(settings.ChannelRestrictions) == 0 {
+		utils.SendMessage(msg.ChannelID, "restrictions.no-channel-restrictions")
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color: 0x0FADED, // blueish
+		Author: &discordgo.MessageEmbedAuthor{
+			Name: utils.Geti18nText("restrictions.channels-title"),
+		},
+	}
+
+	for target, restriction := range settings.ChannelRestrictions {
+		var lines []string
+		if len(restriction.AllowedChannelIDs) > 0 {
+			lines = append(lines, utils.Geti18nTextF("restrictions.allowed-in", mentionChannels(restriction.AllowedChannelIDs)))
+		}
+		if len(restriction.DeniedChannelIDs) > 0 {
+			lines = append(lines, utils.Geti18nTextF("restrictions.denied-in", mentionChannels(restriction.DeniedChannelIDs)))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   strings.Replace(target, ":", " ", 1),
+			Value:  strings.Join(lines, "\n"),
+			Inline: false,
+		})
+	}
+
+	// sort fields by plugin or command
+	sort.Slice(embed.Fields, func(i, j int) bool {
+		return embed.Fields[i].Name < embed.Fields[j].Name
+	})
+
+	utils.SendPagedMessage(msg, embed, 10)
+}
+
+// allowInChannels only allows a plugin or command in the mentioned channels
+func (s *Settings) allowInChannels(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	s.updateChannelRestriction(msg, content, func(restriction *models.ChannelRestriction, channelIDs []string) {
+		restriction.AllowedChannelIDs = append(utils.RemoveStrings(restriction.AllowedChannelIDs, channelIDs), channelIDs...)
+		restriction.DeniedChannelIDs = utils.RemoveStrings(restriction.DeniedChannelIDs, channelIDs)
+	})
+}
+
+// denyInChannels stops a plugin or command from being used in the mentioned channels
+func (s *Settings) denyInChannels(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	s.updateChannelRestriction(msg, content, func(restriction *models.ChannelRestriction, channelIDs []string) {
+		restriction.DeniedChannelIDs = append(utils.RemoveStrings(restriction.DeniedChannelIDs, channelIDs), channelIDs...)
+		restriction.AllowedChannelIDs = utils.RemoveStrings(restriction.AllowedChannelIDs, channelIDs)
+	})
+}
+
+// clearChannelRestrictions lets a plugin or command be used in every channel again
+func (s *Settings) clearChannelRestrictions(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	targetType, targetName, ok := s.findRestrictionTarget(msg, content)
+	if !ok {
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	delete(settings.ChannelRestrictions, targetType+":"+targetName)
+
+	if saveSettings(msg, settings) {
+		utils.SendMessagef(msg.ChannelID, "restrictions.channels-cleared", targetType, targetName)
+	}
+}
+
+// updateChannelRestriction applies the update to the restriction of the plugin or command in the content
+func (s *Settings) updateChannelRestriction(msg *discordgo.Message, content string, update func(*models.ChannelRestriction, []string)) {
+	var channelIDs []string
+	for _, match := range channelMentionRegex.FindAllStringSubmatch(content, -1) {
+		channelIDs = append(channelIDs, match[1])
+	}
+	if len(channelIDs) == 0 {
+		utils.SendMessage(msg.ChannelID, "restrictions.no-channels-mentioned")
+		return
+	}
+
+	targetType, targetName, ok := s.findRestrictionTarget(msg, content)
+	if !ok {
+		return
+	}
+
+	settings := utils.GetGuildSettings(utils.GetGuildIDFromMessage(msg))
+	if settings.ChannelRestrictions == nil {
+		settings.ChannelRestrictions = make(map[string]models.ChannelRestriction)
+	}
+
+	restriction := settings.ChannelRestrictions[targetType+":"+targetName]
+	update(&restriction, channelIDs)
+	settings.ChannelRestrictions[targetType+":"+targetName] = restriction
+
+	if saveSettings(msg, settings) {
+		utils.SendMessagef(msg.ChannelID, "restrictions.channels-updated", targetType, targetName)
+	}
+}
+
+// findRestrictionTarget finds the plugin or command named in the content. ex: "plugin music" or "command biasgame multi"
+//   returns the target type, "plugin" or "command", and the plugin name or command path.
+//   will let the user know if the target does not exist or can't be restricted
+func (s *Settings) findRestrictionTarget(msg *discordgo.Message, content string) (string, string, bool) {
+	commandArgs := strings.Fields(channelMentionRegex.ReplaceAllString(content, ""))
+	if len(commandArgs) < 2 {
+		utils.SendMessage(msg.ChannelID, "restrictions.invalid-target")
+		return "", "", false
+	}
+
+	targetType := strings.ToLower(commandArgs[0])
+	targetName := strings.ToLower(strings.Join(commandArgs[1:], " "))
+
+	switch targetType {
+	case "plugin":
+		if !utils.ContainsString(s.Registry.Plugins(), targetName) {
+			utils.SendMessagef(msg.ChannelID, "restrictions.unknown-plugin", targetName)
+			return "", "", false
+		}
+		if targetName == SETTINGS_PLUGIN_NAME {
+			utils.SendMessage(msg.ChannelID, "restrictions.cant-restrict-settings")
+			return "", "", false
+		}
+
+	case "command":
+		commandPath, ok := s.findCommandPath(msg, targetName)
+		if !ok {
+			return "", "", false
+		}
+
+		// settings commands can't be restricted so admins can't lock themselves out
+		if registeredCommand, _ := s.Registry.Find(strings.Fields(commandPath)[0]); registeredCommand.Plugin() == SETTINGS_PLUGIN_NAME {
+			utils.SendMessage(msg.ChannelID, "restrictions.cant-restrict-settings")
+			return "", "", false
+		}
+		targetName = commandPath
+
+	default:
+		utils.SendMessage(msg.ChannelID, "restrictions.invalid-target")
+		return "", "", false
+	}
+
+	return targetType, targetName, true
+}
+
+// mentionChannels returns the channels as a comma delimited list of channel mentions
+func mentionChannels(channelIDs []string) string {
+	var mentions []string
+	for _, channelID := range channelIDs {
+		mentions = append(mentions, "<#"+channelID+">")
+	}
+
+	return strings.Join(mentions, ", ")
+}
@@ -0,0 +1,74 @@
+package plugins
+
+import (
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Plugin lets bot owners check the health of the storage the bot uses
+type Status struct{}
+
+func (s *Status) Name() string {
+	return "status"
+}
+
+func (s *Status) InitPlugin() {}
+
+// Commands handled by this plugin
+func (s *Status) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "status",
+			Usage:       "status",
+			Description: "help.descriptions.status",
+			Access:      commands.AccessBotOwner,
+			Handler:     s.Action,
+		},
+	}
+}
+
+// Main entry point for plugin
+func (s *Status) Action(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	health := cache.GetStorageHealth()
+
+	embed := &discordgo.MessageEmbed{
+		Color: 0x0FADED, // blueish
+		Author: &discordgo.MessageEmbedAuthor{
+			Name: utils.Geti18nText("status.title"),
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: utils.Geti18nText("status.storage-backend"), Value: health.Backend, Inline: true},
+			{Name: utils.Geti18nText("status.last-checked"), Value: formatStatusTime(health.LastChecked), Inline: true},
+		},
+	}
+
+	if health.Healthy {
+		embed.Description = utils.Geti18nText("status.storage-healthy")
+	} else {
+		embed.Color = 0xE74C3C // red
+		embed.Description = utils.Geti18nTextF("status.storage-down", formatStatusTime(health.DownSince))
+	}
+
+	if health.LastError != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   utils.Geti18nText("status.last-error"),
+			Value:  health.LastError,
+			Inline: false,
+		})
+	}
+
+	utils.SendEmbed(msg.ChannelID, embed)
+}
+
+// formatStatusTime formats the time in utc, discord embeds can't have empty field values
+func formatStatusTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.UTC().Format("2006-01-02 15:04:05 UTC")
+}
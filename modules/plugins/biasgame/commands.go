@@ -0,0 +1,364 @@
+package biasgame
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/modules/commands/args"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Commands handled by the bias game
+func (b *BiasGame) Commands() []*commands.Command {
+	return []*commands.Command{
+		{
+			Name:        "biasgame",
+			Usage:       "biasgame [boy/girl/mixed] [32/64/128/256]",
+			Description: "help.descriptions.biasgame",
+			Examples:    []string{"biasgame", "biasgame boy", "biasgame girl 64", "biasgame 128"},
+			Handler:     whenGameIsReady(startSingleGameCommand),
+			SubCommands: []*commands.Command{
+				{
+					Name:        "stats",
+					Usage:       "biasgame stats [rounds won/rounds lost] [group] [server/global/@user] [multi] [boy/girl] [incomplete] [today/this week/last 30 days/from 2018-01-01 to 2018-01-31]",
+					Description: "help.descriptions.biasgame-stats",
+					Examples:    []string{"biasgame stats", "biasgame stats server group", "biasgame stats rounds won global", "biasgame stats server this month", "biasgame stats last 7 days", "biasgame stats from 2018-06-01 to 2018-06-30", "biasgame stats rounds lost incomplete"},
+					Handler:     whenGameIsReady(statsCommand),
+				},
+				{
+					Name:        "rankings",
+					Usage:       "biasgame rankings",
+					Description: "help.descriptions.biasgame-rankings",
+					Handler:     whenGameIsReady(rankingsCommand),
+				},
+				{
+					Name:        "ratings",
+					Usage:       "biasgame ratings [server/global/@user] [boy/girl]",
+					Description: "help.descriptions.biasgame-ratings",
+					Examples:    []string{"biasgame ratings", "biasgame ratings server girl", "biasgame ratings @user"},
+					Handler:     whenGameIsReady(ratingsCommand),
+					SubCommands: []*commands.Command{
+						{
+							Name:        "rebuild",
+							Usage:       "biasgame ratings rebuild",
+							Description: "help.descriptions.biasgame-ratings-rebuild",
+							Access:      commands.AccessBotOwner,
+							Handler:     whenGameIsReady(rebuildRatingsCommand),
+						},
+					},
+				},
+				{
+					Name:        "suggest",
+					Usage:       "biasgame suggest [boy/girl] \"group name\" \"idol name\" [url to image]",
+					Description: "help.descriptions.biasgame-suggest",
+					Examples:    []string{"biasgame suggest girl \"PRISTIN\" \"Nayoung\" https://cdn.discordapp.com/attachments/420049316615553026/420056295618510849/unknown.png"},
+					Handler:     whenGameIsReady(suggestCommand),
+				},
+				{
+					Name:        "current",
+					Usage:       "biasgame current [@user]",
+					Description: "help.descriptions.biasgame-current",
+					Handler:     whenGameIsReady(currentGameCommand),
+				},
+				{
+					Name:        "quit",
+					Usage:       "biasgame quit",
+					Description: "help.descriptions.biasgame-quit",
+					Handler:     whenGameIsReady(quitGameCommand),
+				},
+				{
+					Name:        "undo",
+					Usage:       "biasgame undo",
+					Description: "help.descriptions.biasgame-undo",
+					Handler:     whenGameIsReady(undoVoteCommand),
+				},
+				{
+					Name:        "restart",
+					Usage:       "biasgame restart [boy/girl/mixed] [32/64/128/256]",
+					Description: "help.descriptions.biasgame-restart",
+					Examples:    []string{"biasgame restart", "biasgame restart boy 64"},
+					Handler:     whenGameIsReady(restartGameCommand),
+				},
+				{
+					Name:        "multi",
+					Usage:       "biasgame multi [boy/girl/mixed]",
+					Description: "help.descriptions.biasgame-multi",
+					Examples:    []string{"biasgame multi", "biasgame multi mixed"},
+					Handler:     whenGameIsReady(multiGameCommand),
+				},
+				{
+					Name:        "idols",
+					Usage:       "biasgame idols",
+					Description: "help.descriptions.biasgame-idols",
+					Handler:     whenGameIsReady(idolsCommand),
+				},
+				{
+					Name:        "refresh-images",
+					Usage:       "biasgame refresh-images",
+					Description: "help.descriptions.biasgame-refresh-images",
+					Access:      commands.AccessBotOwner,
+					Handler:     whenGameIsReady(refreshImagesCommand),
+				},
+			},
+		},
+		{
+			// edit is used for changing details of suggestions
+			Name:    "edit",
+			Usage:   "edit [name/group/gender/notes] new field value...",
+			Handler: whenGameIsReady(editSuggestionCommand),
+			Access:  commands.AccessGuildAdmin,
+			Hidden:  true,
+		},
+	}
+}
+
+// whenGameIsReady wraps a command handler so it will only run once the game is ready
+func whenGameIsReady(handler commands.Handler) commands.Handler {
+	return func(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+
+		if !isGameConfigured() {
+			utils.SendMessage(msg.ChannelID, "biasgame.game.not-configured")
+			return
+		}
+
+		// images, suggestions, and stat set up are done async when bot starts up
+		//   make sure game is ready before trying to process any commands
+		if !isGameReady() {
+			utils.SendMessage(msg.ChannelID, "biasgame.game.game-not-ready")
+			return
+		}
+
+		handler(command, content, msg, session)
+	}
+}
+
+// startSingleGameCommand starts or resumes a single player game. game gender and size are optional
+func startSingleGameCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	gameGender, gameSize, ok := parseSingleGameOptions(msg, content, "biasgame")
+	if !ok {
+		return
+	}
+
+	startOrResumeSinglePlayerGame(msg, gameGender, gameSize)
+}
+
+// quitGameCommand ends the users single player game
+func quitGameCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	game, ok := runningGames.getSingleGame(msg.Author.ID)
+	if !ok || !game.update(game.endAbandonedGame) {
+		utils.SendMessage(msg.ChannelID, "biasgame.current.no-running-game")
+		return
+	}
+
+	utils.SendMessage(msg.ChannelID, "biasgame.game.quit")
+}
+
+// undoVoteCommand takes back the last vote of the users single player game
+func undoVoteCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	game, ok := runningGames.getSingleGame(msg.Author.ID)
+	if !ok {
+		utils.SendMessage(msg.ChannelID, "biasgame.current.no-running-game")
+		return
+	}
+
+	var undone bool
+	running := game.update(func() {
+		// the round is sent again where the command was used, like resuming a game
+		game.channelID = msg.ChannelID
+		undone = game.undoLastVote()
+	})
+	if !running {
+		utils.SendMessage(msg.ChannelID, "biasgame.current.no-running-game")
+		return
+	}
+	if !undone {
+		utils.SendMessage(msg.ChannelID, "biasgame.game.nothing-to-undo")
+		return
+	}
+
+	game.sendBiasGameRound()
+}
+
+// restartGameCommand ends the users single player game, if they have one, and starts a new one
+func restartGameCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	gameGender, gameSize, ok := parseSingleGameOptions(msg, content, "biasgame restart")
+	if !ok {
+		return
+	}
+
+	if game, ok := runningGames.getSingleGame(msg.Author.ID); ok {
+		game.update(game.endAbandonedGame)
+	}
+
+	startOrResumeSinglePlayerGame(msg, gameGender, gameSize)
+}
+
+// parseSingleGameOptions reads the gender and size of a single player game, sending the problem to the user if they're invalid
+func parseSingleGameOptions(msg *discordgo.Message, content string, commandName string) (string, int, bool) {
+	commandArgs := args.Parse(content)
+	gameConfig := getBiasGameConfig().ForGuild(utils.GetGuildIDFromMessage(msg))
+	minGameSize, maxGameSize := getGameSizeLimits(gameConfig.GameSizes)
+
+	gameGender := commandArgs.OptionalEnum("gender", biasGameGenders, "girl")
+	gameSize := commandArgs.OptionalInt("game size", minGameSize, maxGameSize, gameConfig.DefaultGameSize)
+	if err := commandArgs.Done(); err != nil {
+		args.SendError(msg, err, commandName)
+		return "", 0, false
+	}
+
+	// check if the game size the user wants is valid
+	if !isAllowedGameSize(gameConfig.GameSizes, gameSize) {
+		utils.SendMessagef(msg.ChannelID, "biasgame.game.invalid-game-size", joinGameSizes(gameConfig.GameSizes))
+		return "", 0, false
+	}
+
+	return gameGender, gameSize, true
+}
+
+// statsCommand displays game winner or round stats
+func statsCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	options, err := parseStatsOptions(msg, content)
+	if err != nil {
+		args.SendError(msg, err, "biasgame stats")
+		return
+	}
+
+	displayBiasGameStats(msg, options)
+}
+
+// rankingsCommand displays the single game user rankings
+func rankingsCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	showSingleGameRankings(msg)
+}
+
+// suggestCommand processes an idol image suggestion
+func suggestCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	if suggestionChannelID == "" {
+		utils.SendMessage(msg.ChannelID, "biasgame.suggestion.turned-off")
+		return
+	}
+
+	commandArgs := args.Parse(content)
+
+	gender := commandArgs.Enum("gender", suggestionGenders)
+	groupName := commandArgs.String("group name")
+	idolName := commandArgs.String("idol name")
+
+	// the image can be attached instead of linked
+	var imageURL string
+	if len(msg.Attachments) == 1 {
+		imageURL = msg.Attachments[0].URL
+	} else {
+		imageURL = commandArgs.String("url to image")
+	}
+
+	if err := commandArgs.Done(); err != nil {
+		args.SendError(msg, err, "biasgame suggest")
+		return
+	}
+
+	// create map of group => idols in group
+	groupIdolMap := make(map[string][]string)
+	for _, bias := range getAllBiasChoices() {
+		groupIdolMap[bias.groupName] = append(groupIdolMap[bias.groupName], bias.biasName)
+	}
+
+	ProcessImageSuggestion(msg, gender, groupName, idolName, imageURL, groupIdolMap)
+}
+
+// currentGameCommand displays the rounds of the users currently running game, or the game of the mentioned user
+func currentGameCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	commandArgs := args.Parse(content)
+
+	userPlayingGame := msg.Author
+	if userID, ok := commandArgs.OptionalUser("user"); ok {
+		userPlayingGame = getMentionedUser(msg, userID)
+	}
+
+	if err := commandArgs.Done(); err != nil {
+		args.SendError(msg, err, "biasgame current")
+		return
+	}
+
+	displayCurrentGameStats(msg, userPlayingGame)
+}
+
+// multiGameCommand starts a multi player game in the channel
+func multiGameCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	commandArgs := args.Parse(content)
+
+	gameGender := commandArgs.OptionalEnum("gender", biasGameGenders, "girl")
+	if err := commandArgs.Done(); err != nil {
+		args.SendError(msg, err, "biasgame multi")
+		return
+	}
+
+	startMultiPlayerGame(msg, gameGender)
+}
+
+// idolsCommand lists all idols in the game
+func idolsCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	listIdolsInGame(msg)
+}
+
+// refreshImagesCommand reloads all idol images from the image source
+func refreshImagesCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	message, _ := utils.SendMessage(msg.ChannelID, "biasgame.refresh.refresing")
+	refreshBiasChoices()
+
+	cache.GetDiscordSession().ChannelMessageDelete(msg.ChannelID, message.ID)
+	utils.SendMessage(msg.ChannelID, "biasgame.refresh.refresh-done")
+}
+
+// editSuggestionCommand changes details of the current suggestion
+func editSuggestionCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	commandArgs := args.Parse(content)
+
+	fieldToUpdate := commandArgs.Enum("field", editableSuggestionFields)
+	fieldValue := commandArgs.Rest("new field value")
+	if err := commandArgs.Done(); err != nil {
+		args.SendError(msg, err, "edit")
+		return
+	}
+
+	UpdateSuggestionDetails(msg, fieldToUpdate, fieldValue)
+}
+
+// getGameSizeLimits returns the smallest and largest of the game sizes
+func getGameSizeLimits(gameSizes []int) (int, int) {
+	minGameSize, maxGameSize := 0, 0
+	for _, gameSize := range gameSizes {
+		if minGameSize == 0 || gameSize < minGameSize {
+			minGameSize = gameSize
+		}
+		if gameSize > maxGameSize {
+			maxGameSize = gameSize
+		}
+	}
+
+	return minGameSize, maxGameSize
+}
+
+// isAllowedGameSize checks if the game size is one of the allowed game sizes
+func isAllowedGameSize(gameSizes []int, gameSize int) bool {
+	for _, allowedGameSize := range gameSizes {
+		if allowedGameSize == gameSize {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinGameSizes returns the game sizes comma separated
+func joinGameSizes(gameSizes []int) string {
+	var sizes []string
+	for _, gameSize := range gameSizes {
+		sizes = append(sizes, strconv.Itoa(gameSize))
+	}
+
+	return strings.Join(sizes, ", ")
+}
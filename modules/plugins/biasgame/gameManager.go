@@ -0,0 +1,159 @@
+package biasgame
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// gameManager keeps track of the running games.
+//  the manager lock only guards which games are running, not the games themselves. a single game has its own lock,
+//  use update to read or change it. a multi game is only changed by the goroutine that runs its rounds.
+//  a game may use the manager while it is locked, so never lock a game while holding the manager lock
+type gameManager struct {
+	sync.RWMutex
+	singleGames map[string]*singleBiasGame // user id => game
+	multiGames  map[string]*multiBiasGame  // channel id => game
+}
+
+// runningGames holds every single and multi game being played
+var runningGames = newGameManager()
+
+// set to 1 once the images are loaded and the games are restored, back to 0 when the bot shuts down
+var gameReady int32
+
+// holds all available idols in the game. replaced instead of changed so games can keep using the choices they were given
+var (
+	allBiasChoices   []*biasChoice
+	biasChoicesMutex sync.RWMutex
+)
+
+func newGameManager() *gameManager {
+	return &gameManager{
+		singleGames: make(map[string]*singleBiasGame),
+		multiGames:  make(map[string]*multiBiasGame),
+	}
+}
+
+// getSingleGame returns the game the user is playing
+func (m *gameManager) getSingleGame(userID string) (*singleBiasGame, bool) {
+	m.RLock()
+	defer m.RUnlock()
+
+	game, ok := m.singleGames[userID]
+	return game, ok
+}
+
+// addSingleGame adds the game unless the user already has one, in which case the users game is returned instead
+func (m *gameManager) addSingleGame(game *singleBiasGame) (*singleBiasGame, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	if runningGame, ok := m.singleGames[game.user.ID]; ok {
+		return runningGame, false
+	}
+
+	m.singleGames[game.user.ID] = game
+	return game, true
+}
+
+// removeSingleGame removes the game. a newer game of the same user is left alone
+func (m *gameManager) removeSingleGame(game *singleBiasGame) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.singleGames[game.user.ID] == game {
+		delete(m.singleGames, game.user.ID)
+	}
+}
+
+// getSingleGames returns every running single game
+func (m *gameManager) getSingleGames() []*singleBiasGame {
+	m.RLock()
+	defer m.RUnlock()
+
+	games := make([]*singleBiasGame, 0, len(m.singleGames))
+	for _, game := range m.singleGames {
+		games = append(games, game)
+	}
+
+	return games
+}
+
+// addMultiGame adds the game unless its channel already has a multi game running
+func (m *gameManager) addMultiGame(game *multiBiasGame) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.multiGames[game.channelID]; ok {
+		return false
+	}
+
+	m.multiGames[game.channelID] = game
+	return true
+}
+
+// removeMultiGame removes the game. a newer game in the same channel is left alone
+func (m *gameManager) removeMultiGame(game *multiBiasGame) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.multiGames[game.channelID] == game {
+		delete(m.multiGames, game.channelID)
+	}
+}
+
+// getMultiGames returns every running multi game
+func (m *gameManager) getMultiGames() []*multiBiasGame {
+	m.RLock()
+	defer m.RUnlock()
+
+	games := make([]*multiBiasGame, 0, len(m.multiGames))
+	for _, game := range m.multiGames {
+		games = append(games, game)
+	}
+
+	return games
+}
+
+// update calls fn with the game locked. returns false without calling fn if the game has already ended
+func (g *singleBiasGame) update(fn func()) bool {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.ended {
+		return false
+	}
+
+	fn()
+	return true
+}
+
+// isGameReady returns true if commands, votes, and multi game rounds can be processed
+func isGameReady() bool {
+	return atomic.LoadInt32(&gameReady) == 1
+}
+
+// setGameReady starts or stops commands, votes, and multi game rounds from being processed
+func setGameReady(ready bool) {
+	if ready {
+		atomic.StoreInt32(&gameReady, 1)
+	} else {
+		atomic.StoreInt32(&gameReady, 0)
+	}
+}
+
+// getAllBiasChoices returns the idols in the game. the list must not be changed
+func getAllBiasChoices() []*biasChoice {
+	biasChoicesMutex.RLock()
+	defer biasChoicesMutex.RUnlock()
+
+	return allBiasChoices
+}
+
+// setAllBiasChoices replaces the idols in the game
+func setAllBiasChoices(biasChoices []*biasChoice) {
+	biasChoicesMutex.Lock()
+	defer biasChoicesMutex.Unlock()
+
+	allBiasChoices = biasChoices
+}
@@ -0,0 +1,238 @@
+package biasgame
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/storage"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	TEST_GAME_SIZE     = 16
+	TEST_GAME_COUNT    = 20
+	TEST_GUILD         = "guild"
+	TEST_ROUND_MESSAGE = "round"
+)
+
+// setupGameManagerTest gives the games in memory storage and a discord state with the channels of the test games, and clears the running games.
+//  finished and abandoned games are saved to the memory storage, nothing is sent to discord
+func setupGameManagerTest(t *testing.T) {
+	cache.SetAppConfig(&models.AppConfig{BiasGame: models.BiasGameConfig{RecordAbandonedGames: true}})
+	cache.SetRepositories(storage.NewMemoryRepositories())
+
+	state := discordgo.NewState()
+	guild := &discordgo.Guild{ID: TEST_GUILD}
+	for i := 0; i < TEST_GAME_COUNT; i++ {
+		guild.Channels = append(guild.Channels, &discordgo.Channel{ID: "channel" + fmt.Sprint(i), GuildID: TEST_GUILD})
+	}
+	if err := state.GuildAdd(guild); err != nil {
+		t.Fatal(err)
+	}
+	cache.SetDiscordSession(&discordgo.Session{State: state})
+
+	runningGames = newGameManager()
+}
+
+// checkSavedGames checks every game that had rounds was saved once, with its winner if it had one
+func checkSavedGames(t *testing.T, games []*singleBiasGame) {
+	pendingStatsRecords.Wait()
+
+	savedGames, err := cache.GetRepositories().Games.FindGames(models.BiasGameFilter{IncludeIncomplete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedByUser := make(map[string]models.BiasGameEntry)
+	for _, savedGame := range savedGames {
+		if _, ok := savedByUser[savedGame.UserID]; ok {
+			t.Errorf("game of user %s was saved more than once", savedGame.UserID)
+		}
+		savedByUser[savedGame.UserID] = savedGame
+	}
+
+	for _, game := range games {
+		savedGame, saved := savedByUser[game.user.ID]
+		if len(game.roundWinners) == 0 {
+			if saved {
+				t.Errorf("game of user %s was saved without any rounds", game.user.ID)
+			}
+			continue
+		}
+
+		if !saved {
+			t.Errorf("game of user %s was not saved", game.user.ID)
+			continue
+		}
+		if savedGame.GuildID != TEST_GUILD || len(savedGame.RoundWinners) != len(game.roundWinners) {
+			t.Errorf("game of user %s was saved with %d rounds in guild %q, expected %d rounds", game.user.ID, len(savedGame.RoundWinners), savedGame.GuildID, len(game.roundWinners))
+		}
+		if savedGame.Incomplete != (game.gameWinnerBias == nil) {
+			t.Errorf("game of user %s was saved with incomplete %t", game.user.ID, savedGame.Incomplete)
+		}
+		if game.gameWinnerBias != nil && savedGame.GameWinner.Name != game.gameWinnerBias.biasName {
+			t.Errorf("game of user %s was saved with winner %s, expected %s", game.user.ID, savedGame.GameWinner.Name, game.gameWinnerBias.biasName)
+		}
+	}
+}
+
+// newTestGame makes a single game for the user with a queue of made up idols
+func newTestGame(userID string) *singleBiasGame {
+	game := &singleBiasGame{
+		user:           &discordgo.User{ID: userID},
+		channelID:      "channel" + userID,
+		idolsRemaining: TEST_GAME_SIZE,
+		gender:         "girl",
+		gameImageIndex: make(map[string]int),
+	}
+
+	for i := 0; i < TEST_GAME_SIZE; i++ {
+		game.biasQueue = append(game.biasQueue, &biasChoice{
+			fileName:  fmt.Sprintf("Group_Idol%d.png", i),
+			groupName: "Group",
+			biasName:  fmt.Sprintf("Idol%d", i),
+			gender:    "girl",
+		})
+	}
+
+	return game
+}
+
+// voteInGame votes with the emoji on the current round of the game. returns false if the game has ended.
+//  stands in for a round being sent, since there is no discord to send it to
+func voteInGame(game *singleBiasGame, emoji string) bool {
+	return game.update(func() {
+		game.lastRoundMessage = &discordgo.Message{ID: TEST_ROUND_MESSAGE}
+		game.readyForReaction = true
+
+		game.processVote(&discordgo.MessageReactionAdd{MessageReaction: &discordgo.MessageReaction{
+			UserID:    game.user.ID,
+			MessageID: TEST_ROUND_MESSAGE,
+			Emoji:     discordgo.Emoji{Name: emoji},
+		}})
+	})
+}
+
+func TestGameManagerConcurrentGames(t *testing.T) {
+	setupGameManagerTest(t)
+
+	var games []*singleBiasGame
+	for i := 0; i < TEST_GAME_COUNT; i++ {
+		game, added := runningGames.addSingleGame(newTestGame(fmt.Sprint(i)))
+		if !added {
+			t.Fatalf("game %d was not added", i)
+		}
+		games = append(games, game)
+	}
+
+	// read the games the way the timeout check and current game command do while they're played
+	done := make(chan bool)
+	readersDone := make(chan bool)
+	go func() {
+		defer close(readersDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			for _, game := range runningGames.getSingleGames() {
+				game.update(func() {
+					_ = len(game.roundWinners) + game.idolsRemaining
+				})
+			}
+		}
+	}()
+
+	// every game votes left, right, then takes the right vote back until it has a winner
+	var wg sync.WaitGroup
+	emojis := []string{LEFT_ARROW_EMOJI, RIGHT_ARROW_EMOJI, UNDO_EMOJI}
+	for _, game := range games {
+		wg.Add(1)
+		go func(game *singleBiasGame) {
+			defer wg.Done()
+			for vote := 0; voteInGame(game, emojis[vote%len(emojis)]); vote++ {
+			}
+		}(game)
+	}
+
+	wg.Wait()
+	close(done)
+	<-readersDone
+	checkSavedGames(t, games)
+
+	for _, game := range games {
+		if game.gameWinnerBias == nil || len(game.roundWinners) != TEST_GAME_SIZE-1 {
+			t.Errorf("game of user %s ended after %d rounds without a winner", game.user.ID, len(game.roundWinners))
+		}
+	}
+	if runningGames := runningGames.getSingleGames(); len(runningGames) != 0 {
+		t.Errorf("%d games are still running after they were won", len(runningGames))
+	}
+}
+
+func TestGameManagerEndWhileVoting(t *testing.T) {
+	setupGameManagerTest(t)
+
+	var games []*singleBiasGame
+	var wg sync.WaitGroup
+	for i := 0; i < TEST_GAME_COUNT; i++ {
+		game, _ := runningGames.addSingleGame(newTestGame(fmt.Sprint(i)))
+		games = append(games, game)
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for voteInGame(game, LEFT_ARROW_EMOJI) {
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			game.update(func() {
+				// there is no round message to delete
+				game.lastRoundMessage = nil
+				game.endAbandonedGame()
+			})
+		}()
+	}
+
+	wg.Wait()
+	checkSavedGames(t, games)
+
+	if games := runningGames.getSingleGames(); len(games) != 0 {
+		t.Errorf("%d games are still running after they were ended", len(games))
+	}
+}
+
+func TestGameManagerOneGameEach(t *testing.T) {
+	setupGameManagerTest(t)
+
+	firstGame, _ := runningGames.addSingleGame(newTestGame("user"))
+	game, added := runningGames.addSingleGame(newTestGame("user"))
+	if added || game != firstGame {
+		t.Error("a second game was added for the user instead of returning the first")
+	}
+
+	// ending an old game must not remove the users newer game
+	firstGame.update(firstGame.end)
+	secondGame, _ := runningGames.addSingleGame(newTestGame("user"))
+	runningGames.removeSingleGame(firstGame)
+	if game, ok := runningGames.getSingleGame("user"); !ok || game != secondGame {
+		t.Error("removing an ended game removed the users newer game")
+	}
+	if firstGame.update(func() {}) {
+		t.Error("an ended game could still be updated")
+	}
+
+	multiGame := &multiBiasGame{channelID: "channel"}
+	if !runningGames.addMultiGame(multiGame) || runningGames.addMultiGame(&multiBiasGame{channelID: "channel"}) {
+		t.Error("a channel can only have one multi game")
+	}
+	runningGames.removeMultiGame(multiGame)
+	if len(runningGames.getMultiGames()) != 0 {
+		t.Error("the multi game was not removed")
+	}
+}
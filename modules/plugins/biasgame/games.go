@@ -2,16 +2,18 @@ package biasgame
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"image"
 	"image/draw"
 	"image/png"
 	"math/rand"
-	"strconv"
-	"strings"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
 	"github.com/Snakeyesz/snek-bot/utils"
 	"github.com/bwmarrin/discordgo"
 	"github.com/nfnt/resize"
@@ -20,12 +22,9 @@ import (
 type BiasGame struct{}
 
 type biasChoice struct {
-	// info directly from google drive
-	fileName       string
-	driveId        string
-	webViewLink    string
-	webContentLink string
-	gender         string
+	// info from the image source
+	fileName string
+	gender   string
 
 	// image
 	biasImages []image.Image
@@ -36,17 +35,23 @@ type biasChoice struct {
 }
 
 type singleBiasGame struct {
+	sync.Mutex       // held while the game is read or changed, see update
+	ended            bool
 	user             *discordgo.User
 	channelID        string
 	roundLosers      []*biasChoice
 	roundWinners     []*biasChoice
+	roundWinnerSides []int // 0 if the left idol won the round, 1 for the right. used to undo votes
 	biasQueue        []*biasChoice
 	topEight         []*biasChoice
 	gameWinnerBias   *biasChoice
 	idolsRemaining   int
 	lastRoundMessage *discordgo.Message
 	readyForReaction bool   // used to make sure multiple reactions aren't counted
+	roundNumber      int    // counts the rounds sent, a round that was replaced while it was being sent is deleted
 	gender           string // girl, boy, mixed
+	startedAt        time.Time
+	lastActivity     time.Time // last vote or resume, games inactive for too long are ended
 
 	// a map of fileName => image array position. This is used to make sure that when a random image is selected for a game, that the same image is still used throughout the game
 	gameImageIndex map[string]int
@@ -55,6 +60,7 @@ type singleBiasGame struct {
 type multiBiasGame struct {
 	currentRoundMessageId string // used to find game when reactions are added
 	channelID             string
+	roundDelay            time.Duration // time users have to vote each round
 	roundLosers           []*biasChoice
 	roundWinners          []*biasChoice
 	biasQueue             []*biasChoice
@@ -64,64 +70,48 @@ type multiBiasGame struct {
 	lastRoundMessage      *discordgo.Message
 	gender                string // girl, boy, mixed
 	userIdsInvolved       []string
+	startedAt             time.Time
+	resumeRound           bool // the game was restored on its last round message, count its votes before sending a new round
+	roundsWithoutVotes    int  // rounds in a row nobody voted in, the game is ended after too many
 
 	// a map of fileName => image array position. This is used to make sure that when a random image is selected for a game, that the same image is still used throughout the game
 	gameImageIndex map[string]int
 }
 
 const (
-	DRIVE_SEARCH_TEXT       = "\"%s\" in parents and (mimeType = \"image/gif\" or mimeType = \"image/jpeg\" or mimeType = \"image/png\" or mimeType = \"application/vnd.google-apps.folder\")"
-	GIRLS_FOLDER_ID         = "1CIM6yrvZOKn_R-qWYJ6pISHyq-JQRkja"
-	BOYS_FOLDER_ID          = "1psrhQQaV0kwPhAMtJ7LYT2SWgLoyDb-J"
-	MISC_FOLDER_ID          = "1-HdvH5fiOKuZvPPVkVMILZxkjZKv9x_x"
-	IMAGE_RESIZE_HEIGHT     = 150
-	LEFT_ARROW_EMOJI        = "⬅"
-	RIGHT_ARROW_EMOJI       = "➡"
-	ARROW_FORWARD_EMOJI     = "▶"
-	ARROW_BACKWARD_EMOJI    = "◀"
-	ZERO_WIDTH_SPACE        = "\u200B"
-	BOT_OWNER_ID            = "273639623324991489"
-	MULTIPLAYER_ROUND_DELAY = 5
+	IMAGE_RESIZE_HEIGHT  = 150
+	LEFT_ARROW_EMOJI     = "⬅"
+	RIGHT_ARROW_EMOJI    = "➡"
+	ARROW_FORWARD_EMOJI  = "▶"
+	ARROW_BACKWARD_EMOJI = "◀"
+	UNDO_EMOJI           = "↩"
+	ZERO_WIDTH_SPACE     = "\u200B"
+
+	INACTIVE_GAME_CHECK_INTERVAL = time.Minute
 )
 
-// used to stop commands from going through
-//  before the game is ready after a bot restart
-var gameIsReady = false
-
 // misc images
 var versesImage image.Image
 var winnerBracket image.Image
 var shadowBorder image.Image
 var crown image.Image
 
-// currently running single or multiplyer games
-var currentSinglePlayerGames map[string]*singleBiasGame
-var currentMultiPlayerGames []*multiBiasGame
-
-// holds all available idols in the game
-var allBiasChoices []*biasChoice
-
 // game configs
-var allowedGameSizes map[int]bool
 var biasGameGenders map[string]string
 
 // top 8 bracket
 var bracketImageOffsets map[int]image.Point
 var bracketImageResizeMap map[int]uint
 
+func (b *BiasGame) Name() string {
+	return "biasgame"
+}
+
 // InitPlugin when the bot starts up
 //  this func should never be called again after game is ready
 func (b *BiasGame) InitPlugin() {
 
 	// set global variables
-	currentSinglePlayerGames = make(map[string]*singleBiasGame)
-	allowedGameSizes = map[int]bool{
-		10:  true, // for dev only, remove when game is live
-		32:  true,
-		64:  true,
-		128: true,
-		256: true,
-	}
 	biasGameGenders = map[string]string{
 		"boy":   "boy",
 		"boys":  "boy",
@@ -156,154 +146,121 @@ func (b *BiasGame) InitPlugin() {
 		11: 60, 10: 60, 9: 60, 8: 60,
 	}
 
-	// load all bias images and information
-	refreshBiasChoices()
+	// the game can't run without a place to load the images from
+	if !isGameConfigured() {
+		fmt.Println("Biasgame is turned off. biasgame.girls_folder_id, biasgame.boys_folder_id, and biasgame.misc_folder_id must be set in the config, or biasgame.image_source set to local with an existing biasgame.local_images_path folder")
+		return
+	}
+
+	// load the verses and winnerBracket image, rounds can't be drawn without them
+	if !loadMiscImages() {
+		return
+	}
 
-	// load the verses and winnerBracket image
-	loadMiscImages()
+	// load all bias images and information
+	idolsLoaded := refreshBiasChoices()
 
 	// set up suggestions channel
 	initSuggestionChannel()
 
-	// this line should always be last in this function
-	gameIsReady = true
-}
+	// calculate ratings from games played before ratings were saved
+	go backfillRatings()
 
-// Will validate if the passed command entered is used for this plugin
-func (b *BiasGame) ValidateCommand(command string) bool {
-	validCommands := []string{"biasgame", "edit"}
+	// bring back the games that were running when the bot stopped.
+	//  without idols every game would fail to restore, so the snapshots are kept for the next start
+	var restoredMultiGames []*multiBiasGame
+	if idolsLoaded > 0 {
+		restoredMultiGames = restoreGames()
+	} else {
+		fmt.Println("No idols were loaded, running biasgames will be restored on the next start")
+	}
 
-	for _, v := range validCommands {
-		if v == command {
-			return true
-		}
+	// nothing that needs the game to be loaded should be done before this line
+	setGameReady(true)
+
+	// multi games stop sending rounds while the game isn't ready, so they can only continue now
+	for _, game := range restoredMultiGames {
+		go processRestoredMultiGame(game)
 	}
 
-	return false
+	go endInactiveGames()
 }
 
-// Main Entry point for the plugin
-func (b *BiasGame) Action(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
-
-	// images, suggestions, and stat set up are done async when bot starts up
-	//   make sure game is ready before trying to process any commands
-	if gameIsReady == false {
-		utils.SendMessage(msg.ChannelID, "biasgame.game.game-not-ready")
+// ActionOnReload sets up the new suggestion channel if it was changed in the config.
+//  image changes are loaded with the refresh-images command
+func (b *BiasGame) ActionOnReload() {
+	if !isGameReady() || suggestionChannelID == getBiasGameConfig().SuggestionChannelID {
 		return
 	}
 
-	// process text after the initial command
-	commandArgs := strings.Fields(content)
-	if command == "biasgame" {
-
-		if len(commandArgs) == 0 {
-			// start default bias game
-			singleGame := createOrGetSinglePlayerGame(msg, "girl", 32)
-			singleGame.sendBiasGameRound()
-
-		} else if commandArgs[0] == "stats" {
-
-			// stats
-			displayBiasGameStats(msg, content)
-
-		} else if commandArgs[0] == "rankings" {
-
-			showSingleGameRankings(msg)
-
-		} else if commandArgs[0] == "suggest" {
-
-			// create map of group => idols in group
-			groupIdolMap := make(map[string][]string)
-			for _, bias := range allBiasChoices {
-				groupIdolMap[bias.groupName] = append(groupIdolMap[bias.groupName], bias.biasName)
-			}
-
-			ProcessImageSuggestion(msg, content, groupIdolMap)
-
-		} else if commandArgs[0] == "current" {
-
-			displayCurrentGameStats(msg)
-
-		} else if commandArgs[0] == "multi" {
-
-			startMultiPlayerGame(msg, commandArgs)
-
-		} else if commandArgs[0] == "idols" {
-
-			listIdolsInGame(msg)
-
-		} else if commandArgs[0] == "refresh-images" {
-
-			// check if the user is the bot owner
-			if msg.Author.ID == BOT_OWNER_ID {
-
-				message, _ := utils.SendMessage(msg.ChannelID, "biasgame.refresh.refresing")
-				refreshBiasChoices()
-
-				cache.GetDiscordSession().ChannelMessageDelete(msg.ChannelID, message.ID)
-				utils.SendMessage(msg.ChannelID, "biasgame.refresh.refresh-done")
-			} else {
-				utils.SendMessage(msg.ChannelID, "biasgame.refresh.not-bot-owner")
-			}
-
-		} else if gameSize, err := strconv.Atoi(commandArgs[0]); err == nil {
+	initSuggestionChannel()
+}
 
-			// check if the game size the user wants is valid
-			if allowedGameSizes[gameSize] {
-				singleGame := createOrGetSinglePlayerGame(msg, "girl", gameSize)
-				singleGame.sendBiasGameRound()
-			} else {
-				utils.SendMessage(msg.ChannelID, "biasgame.game.invalid-game-size")
-				return
-			}
+// Shutdown stops the running games before the bot exits.
+//  players are told their game will continue after the restart and stats from finished games are given time to save
+func (b *BiasGame) Shutdown(ctx context.Context) {
 
-		} else if gameGender, ok := biasGameGenders[commandArgs[0]]; ok {
+	// stops new games, votes, and multi game rounds
+	setGameReady(false)
 
-			// check if the game size the user wants is valid
-			if len(commandArgs) == 2 {
+	// saving stats comes first, players are only told while there is time left
+	waitForPendingStats(ctx)
 
-				gameSize, _ := strconv.Atoi(commandArgs[1])
-				if allowedGameSizes[gameSize] {
-					singleGame := createOrGetSinglePlayerGame(msg, gameGender, gameSize)
-					singleGame.sendBiasGameRound()
-				} else {
-					utils.SendMessage(msg.ChannelID, "biasgame.game.invalid-game-size")
-					return
-				}
-			} else {
-				singleGame := createOrGetSinglePlayerGame(msg, gameGender, 32)
-				singleGame.sendBiasGameRound()
-			}
+	var channelIDs []string
+	for _, game := range runningGames.getSingleGames() {
+		game.update(func() {
+			channelIDs = append(channelIDs, game.channelID)
+		})
+	}
+	for _, game := range runningGames.getMultiGames() {
+		channelIDs = append(channelIDs, game.channelID)
+	}
 
+	for _, channelID := range channelIDs {
+		select {
+		case <-ctx.Done():
+			return
+		default:
 		}
-	} else if command == "edit" { // edit is used for changing details of suggestions
-		fieldToUpdate := commandArgs[0]
-		fieldValue := strings.Join(commandArgs[1:], " ")
-		UpdateSuggestionDetails(msg, fieldToUpdate, fieldValue)
+
+		utils.SendMessage(channelID, "biasgame.game.bot-shutting-down")
 	}
 }
 
 // Called whenever a reaction is added to any message
 func (b *BiasGame) ActionOnReactionAdd(reaction *discordgo.MessageReactionAdd) {
-	if gameIsReady == false {
+	if !isGameReady() {
 		return
 	}
 
 	// confirm the reaction was added to a message for one bias games
-	if game, ok := currentSinglePlayerGames[reaction.UserID]; ok == true {
-		game.processVote(reaction)
-	}
-
-	// check if the reaction was added to a paged message
-	if pagedMessage := utils.GetPagedMessage(reaction.MessageID); pagedMessage != nil {
-		pagedMessage.UpdateMessagePage(reaction)
+	if game, ok := runningGames.getSingleGame(reaction.UserID); ok == true {
+		var voted, won bool
+		game.update(func() {
+			voted = game.processVote(reaction)
+			won = game.ended
+		})
+
+		// messages are sent without the game locked, a won game can't change anymore
+		if won {
+			game.sendWinnerMessage()
+		} else if voted {
+
+			// Sleep a time bit to allow other users to see what was chosen.
+			// This creates conversation while the game is going and makes it a overall better experience
+			//
+			//   This will also allow me to call out and harshly judge players who don't choose nayoung.
+			time.Sleep(time.Second / 5)
+
+			game.sendBiasGameRound()
+		}
 	}
 
 	// check if this was a reaction to a idol suggestion.
 	//  if it was accepted an image will be returned to be added to the biasChoices
-	suggestedImageDriveFile := CheckSuggestionReaction(reaction)
-	if suggestedImageDriveFile != nil {
-		addDriveFileToAllBiases(suggestedImageDriveFile)
+	suggestedImage := CheckSuggestionReaction(reaction)
+	if suggestedImage != nil {
+		addImageToAllBiases(*suggestedImage)
 	}
 }
 
@@ -311,13 +268,33 @@ func (b *BiasGame) ActionOnReactionAdd(reaction *discordgo.MessageReactionAdd) {
 //    SINGLE GAME FUNCTIONS    //
 /////////////////////////////////
 
+// startOrResumeSinglePlayerGame sends the next round of the users game, a new game is made if the user doesn't have one
+func startOrResumeSinglePlayerGame(msg *discordgo.Message, gameGender string, gameSize int) {
+	for {
+		singleGame := createOrGetSinglePlayerGame(msg, gameGender, gameSize)
+		if singleGame == nil {
+			return
+		}
+
+		// update the channel id for the game incase the user tried resuming the game from another server
+		resumed := singleGame.update(func() {
+			singleGame.channelID = msg.ChannelID
+			singleGame.lastActivity = time.Now()
+		})
+		if resumed && singleGame.sendBiasGameRound() {
+			return
+		}
+
+		// the game ended before it could be resumed, a new game will be made
+	}
+}
+
 // createSinglePlayerGame will setup a singleplayer game for the user
 func createOrGetSinglePlayerGame(msg *discordgo.Message, gameGender string, gameSize int) *singleBiasGame {
 	var singleGame *singleBiasGame
 
 	// check if the user has a current game already going.
-	// if so update the channel id for the game incase the user tried starting the game from another server
-	if game, ok := currentSinglePlayerGames[msg.Author.ID]; ok {
+	if game, ok := runningGames.getSingleGame(msg.Author.ID); ok {
 
 		// if the user already had a game going, let them know to avoid confusion if they
 		//   tried starting another game a long time after the first
@@ -328,7 +305,6 @@ func createOrGetSinglePlayerGame(msg *discordgo.Message, gameGender string, game
 		// 	go utils.DeleteImageWithDelay(msg, time.Second*10)
 		// }
 
-		game.channelID = msg.ChannelID
 		singleGame = game
 	} else {
 		var biasChoices []*biasChoice
@@ -336,13 +312,13 @@ func createOrGetSinglePlayerGame(msg *discordgo.Message, gameGender string, game
 		// if this isn't a mixed game then filter all choices by the gender
 		if gameGender != "mixed" {
 
-			for _, bias := range allBiasChoices {
+			for _, bias := range getAllBiasChoices() {
 				if bias.gender == gameGender {
 					biasChoices = append(biasChoices, bias)
 				}
 			}
 		} else {
-			biasChoices = allBiasChoices
+			biasChoices = getAllBiasChoices()
 		}
 
 		// confirm we have enough biases to choose from for the game size this should be
@@ -358,6 +334,8 @@ func createOrGetSinglePlayerGame(msg *discordgo.Message, gameGender string, game
 			idolsRemaining:   gameSize,
 			readyForReaction: false,
 			gender:           gameGender,
+			startedAt:        time.Now(),
+			lastActivity:     time.Now(),
 		}
 		singleGame.gameImageIndex = make(map[string]int)
 
@@ -376,18 +354,23 @@ func createOrGetSinglePlayerGame(msg *discordgo.Message, gameGender string, game
 			}
 		}
 
-		// save game to current running games
-		currentSinglePlayerGames[msg.Author.ID] = singleGame
+		// save game to current running games. if the user started another game at the same time, that game is used
+		singleGame, _ = runningGames.addSingleGame(singleGame)
 	}
 
 	return singleGame
 }
 
-// processVote is called when a valid reaction is added to a game
-func (g *singleBiasGame) processVote(reaction *discordgo.MessageReactionAdd) {
+// processVote is called when a valid reaction is added to a game.
+//  returns true if the vote changed the game, the next round or the winner message still has to be sent
+func (g *singleBiasGame) processVote(reaction *discordgo.MessageReactionAdd) bool {
 
 	// check if reaction was added to the message of the game
-	if g.lastRoundMessage.ID == reaction.MessageID && g.readyForReaction == true {
+	if g.lastRoundMessage != nil && g.lastRoundMessage.ID == reaction.MessageID && g.readyForReaction == true {
+
+		if UNDO_EMOJI == reaction.Emoji.Name {
+			return g.undoLastVote()
+		}
 
 		winnerIndex := 0
 		loserIndex := 0
@@ -406,11 +389,13 @@ func (g *singleBiasGame) processVote(reaction *discordgo.MessageReactionAdd) {
 
 		if validReaction == true {
 			g.readyForReaction = false
+			g.lastActivity = time.Now()
 			g.idolsRemaining--
 
 			// record winners and losers for stats
 			g.roundLosers = append(g.roundLosers, g.biasQueue[loserIndex])
 			g.roundWinners = append(g.roundWinners, g.biasQueue[winnerIndex])
+			g.roundWinnerSides = append(g.roundWinnerSides, winnerIndex)
 
 			// add winner to end of bias queue and remove first two
 			g.biasQueue = append(g.biasQueue, g.biasQueue[winnerIndex])
@@ -420,51 +405,137 @@ func (g *singleBiasGame) processVote(reaction *discordgo.MessageReactionAdd) {
 			if len(g.biasQueue) == 1 {
 
 				g.gameWinnerBias = g.biasQueue[0]
-				g.sendWinnerMessage()
+
+				// end the g. delete from current games
+				g.end()
 
 				// record game stats
+				pendingStatsRecords.Add(1)
 				go recordSingleGamesStats(g)
 
-				// end the g. delete from current games
-				delete(currentSinglePlayerGames, g.user.ID)
-
 			} else {
 
 				// save the last 8 for the chart
 				if len(g.biasQueue) == 8 {
 					g.topEight = g.biasQueue
 				}
-
-				// Sleep a time bit to allow other users to see what was chosen.
-				// This creates conversation while the game is going and makes it a overall better experience
-				//
-				//   This will also allow me to call out and harshly judge players who don't choose nayoung.
-				time.Sleep(time.Second / 5)
-
-				g.sendBiasGameRound()
 			}
 
+			return true
 		}
 	}
+
+	return false
 }
 
-// sendBiasGameRound will send the message for the round
-func (g *singleBiasGame) sendBiasGameRound() {
-	if g == nil {
-		return
+// end stops the game from taking votes and removes it from the running games and storage
+func (g *singleBiasGame) end() {
+	g.ended = true
+	g.readyForReaction = false
+
+	runningGames.removeSingleGame(g)
+	deleteGameSnapshot(g.snapshotKey())
+}
+
+// undoLastVote takes back the last vote, its round has to be sent again.
+//  returns false if there is no vote to undo
+func (g *singleBiasGame) undoLastVote() bool {
+	lastRound := len(g.roundWinners) - 1
+
+	// games restored from before sides were saved can't tell which side the winner was on
+	if lastRound < 0 || len(g.roundWinnerSides) != len(g.roundWinners) {
+		return false
 	}
 
-	// if a round message has been sent, delete before sending the next one
+	g.readyForReaction = false
+	g.lastActivity = time.Now()
+	g.idolsRemaining++
+
+	// put the round back in front of the queue in the order it was shown, and take the winner off the end
+	lastRoundBiases := []*biasChoice{g.roundWinners[lastRound], g.roundLosers[lastRound]}
+	if g.roundWinnerSides[lastRound] == 1 {
+		lastRoundBiases = []*biasChoice{g.roundLosers[lastRound], g.roundWinners[lastRound]}
+	}
+	g.biasQueue = append(lastRoundBiases, g.biasQueue[:len(g.biasQueue)-1]...)
+
+	g.roundWinners = g.roundWinners[:lastRound]
+	g.roundLosers = g.roundLosers[:lastRound]
+	g.roundWinnerSides = g.roundWinnerSides[:lastRound]
+
+	// the top eight is only known once the queue is down to eight
+	if len(g.biasQueue) > 8 {
+		g.topEight = nil
+	}
+
+	return true
+}
+
+// endAbandonedGame ends a game that timed out or was quit. the round message is deleted and
+//  the rounds that were played are saved as an incomplete game if the config asks for it
+func (g *singleBiasGame) endAbandonedGame() {
+	g.end()
+
 	if g.lastRoundMessage != nil {
 		go cache.GetDiscordSession().ChannelMessageDelete(g.lastRoundMessage.ChannelID, g.lastRoundMessage.ID)
 	}
 
-	// combine first bias image with the "vs" image, then combine that image with 2nd bias image
-	img1 := g.biasQueue[0].getRandomBiasImage(&g.gameImageIndex)
-	img2 := g.biasQueue[1].getRandomBiasImage(&g.gameImageIndex)
+	if getBiasGameConfig().RecordAbandonedGames && len(g.roundWinners) > 0 {
+		pendingStatsRecords.Add(1)
+		go recordSingleGamesStats(g)
+	}
+}
 
-	img1 = giveImageShadowBorder(img1, 15, 15)
-	img2 = giveImageShadowBorder(img2, 15, 15)
+// singleGameRound is what's needed to send a round, read from the game while it's locked
+type singleGameRound struct {
+	number           int
+	channelID        string
+	lastRoundMessage *discordgo.Message
+	biases           []*biasChoice
+	images           []image.Image
+	idolsRemaining   int
+	canUndo          bool
+}
+
+// nextRound starts the next round of the game, no votes are taken until it's sent. must be called with the game locked
+func (g *singleBiasGame) nextRound() singleGameRound {
+	g.roundNumber++
+	g.readyForReaction = false
+
+	return singleGameRound{
+		number:           g.roundNumber,
+		channelID:        g.channelID,
+		lastRoundMessage: g.lastRoundMessage,
+		biases:           []*biasChoice{g.biasQueue[0], g.biasQueue[1]},
+		images: []image.Image{
+			g.biasQueue[0].getRandomBiasImage(&g.gameImageIndex),
+			g.biasQueue[1].getRandomBiasImage(&g.gameImageIndex),
+		},
+		idolsRemaining: g.idolsRemaining,
+		canUndo:        len(g.roundWinners) > 0,
+	}
+}
+
+// sendBiasGameRound will send the message for the round. returns false if the game has ended.
+//  must be called without the game locked, it's only locked while the round is read and saved
+//  so votes, timeouts, and shutdown don't wait on the image upload
+func (g *singleBiasGame) sendBiasGameRound() bool {
+	if g == nil {
+		return false
+	}
+
+	var round singleGameRound
+	if !g.update(func() { round = g.nextRound() }) {
+		return false
+	}
+
+	// if a round message has been sent, delete before sending the next one
+	if round.lastRoundMessage != nil {
+		go cache.GetDiscordSession().ChannelMessageDelete(round.lastRoundMessage.ChannelID, round.lastRoundMessage.ID)
+	}
+
+	// combine first bias image with the "vs" image, then combine that image with 2nd bias image
+	img1 := giveImageShadowBorder(round.images[0], 15, 15)
+	img2 := giveImageShadowBorder(round.images[1], 15, 15)
 
 	img1 = utils.CombineTwoImages(img1, versesImage)
 	finalImage := utils.CombineTwoImages(img1, img2)
@@ -472,11 +543,11 @@ func (g *singleBiasGame) sendBiasGameRound() {
 	// create round message
 	messageString := fmt.Sprintf("**@%s**\nIdols Remaining: %d\n%s %s vs %s %s",
 		g.user.Username,
-		g.idolsRemaining,
-		g.biasQueue[0].groupName,
-		g.biasQueue[0].biasName,
-		g.biasQueue[1].groupName,
-		g.biasQueue[1].biasName)
+		round.idolsRemaining,
+		round.biases[0].groupName,
+		round.biases[0].biasName,
+		round.biases[1].groupName,
+		round.biases[1].biasName)
 
 	// encode the combined image and compress it
 	buf := new(bytes.Buffer)
@@ -486,18 +557,38 @@ func (g *singleBiasGame) sendBiasGameRound() {
 	myReader := bytes.NewReader(buf.Bytes())
 
 	// send round message
-	fileSendMsg, err := utils.SendFile(g.channelID, "combined_pic.png", myReader, messageString)
+	fileSendMsg, err := utils.SendFile(round.channelID, "combined_pic.png", myReader, messageString)
 	if err != nil {
-		return
+		return true
 	}
 
-	// add reactions
-	cache.GetDiscordSession().MessageReactionAdd(g.channelID, fileSendMsg.ID, LEFT_ARROW_EMOJI)
-	go cache.GetDiscordSession().MessageReactionAdd(g.channelID, fileSendMsg.ID, RIGHT_ARROW_EMOJI)
+	// add reactions, the undo reaction is only added once there is a vote to undo
+	cache.GetDiscordSession().MessageReactionAdd(fileSendMsg.ChannelID, fileSendMsg.ID, LEFT_ARROW_EMOJI)
+	go func() {
+		cache.GetDiscordSession().MessageReactionAdd(fileSendMsg.ChannelID, fileSendMsg.ID, RIGHT_ARROW_EMOJI)
+		if round.canUndo {
+			cache.GetDiscordSession().MessageReactionAdd(fileSendMsg.ChannelID, fileSendMsg.ID, UNDO_EMOJI)
+		}
+	}()
 
-	// update game state
-	g.lastRoundMessage = fileSendMsg
-	g.readyForReaction = true
+	// update game state, unless the game ended or another round was started while this one was sent
+	var current bool
+	running := g.update(func() {
+		if g.roundNumber != round.number {
+			return
+		}
+
+		current = true
+		g.lastRoundMessage = fileSendMsg
+		g.readyForReaction = true
+
+		g.saveSnapshot()
+	})
+	if !current {
+		go cache.GetDiscordSession().ChannelMessageDelete(fileSendMsg.ChannelID, fileSendMsg.ID)
+	}
+
+	return running
 }
 
 // sendWinnerMessage creates the top eight brackent sends the winning message to the user
@@ -552,45 +643,21 @@ func (g *singleBiasGame) sendWinnerMessage() {
 /////////////////////////////////
 
 // startMultiPlayerGame will create and start a multiplayer game
-func startMultiPlayerGame(msg *discordgo.Message, commandArgs []string) {
+func startMultiPlayerGame(msg *discordgo.Message, gameGender string) {
 	fmt.Println("starting multi game")
 
-	// check if a multi game is already running in the current channel
-	for _, game := range currentMultiPlayerGames {
-		if game.channelID == msg.ChannelID {
-			utils.SendMessage(msg.ChannelID, "biasgame.game.multi-game-running")
-			return
-		}
-	}
-
-	var gameGender string
-	var ok bool
-
-	// if command args are at least 2, check if the 2nd arg is valid gender
-	if len(commandArgs) >= 2 {
-
-		if gameGender, ok = biasGameGenders[commandArgs[1]]; ok == false {
-			// todo: some message probably
-			return
-		}
-	} else {
-
-		// set gender to girl
-		gameGender = "girl"
-	}
-
 	var biasChoices []*biasChoice
 
 	// if this isn't a mixed game then filter all choices by the gender
 	if gameGender != "mixed" {
 
-		for _, bias := range allBiasChoices {
+		for _, bias := range getAllBiasChoices() {
 			if bias.gender == gameGender {
 				biasChoices = append(biasChoices, bias)
 			}
 		}
 	} else {
-		biasChoices = allBiasChoices
+		biasChoices = getAllBiasChoices()
 	}
 
 	// confirm we have enough biases for a multiplayer game
@@ -600,10 +667,13 @@ func startMultiPlayerGame(msg *discordgo.Message, commandArgs []string) {
 	}
 
 	// create new game
+	roundDelaySeconds := getBiasGameConfig().ForGuild(utils.GetGuildIDFromMessage(msg)).MultiRoundDelaySeconds
 	multiGame := &multiBiasGame{
 		channelID:      msg.ChannelID,
+		roundDelay:     time.Duration(roundDelaySeconds * float64(time.Second)),
 		idolsRemaining: 32,
 		gender:         gameGender,
+		startedAt:      time.Now(),
 	}
 	multiGame.gameImageIndex = make(map[string]int)
 
@@ -622,8 +692,11 @@ func startMultiPlayerGame(msg *discordgo.Message, commandArgs []string) {
 		}
 	}
 
-	// save game to current running games
-	currentMultiPlayerGames = append(currentMultiPlayerGames, multiGame)
+	// save game to current running games, unless a multi game is already running in the current channel
+	if !runningGames.addMultiGame(multiGame) {
+		utils.SendMessage(msg.ChannelID, "biasgame.game.multi-game-running")
+		return
+	}
 
 	multiGame.processMultiGame()
 }
@@ -677,6 +750,8 @@ func (g *multiBiasGame) sendMultiBiasGameRound() {
 	// update game state
 	g.currentRoundMessageId = fileSendMsg.ID
 	g.lastRoundMessage = fileSendMsg
+
+	g.saveSnapshot()
 }
 
 // start multi game loop. every 10 seconds count the number of arrow reactions. whichever side has most wins
@@ -684,9 +759,18 @@ func (g *multiBiasGame) processMultiGame() {
 
 	for g.idolsRemaining != 1 {
 
-		// send next rounds and sleep
-		g.sendMultiBiasGameRound()
-		time.Sleep(time.Second * MULTIPLAYER_ROUND_DELAY)
+		// the bot is shutting down, stop sending rounds
+		if !isGameReady() {
+			return
+		}
+
+		// send next rounds and sleep. a restored game counts the votes on the round it was restored on first
+		if g.resumeRound {
+			g.resumeRound = false
+		} else {
+			g.sendMultiBiasGameRound()
+		}
+		time.Sleep(g.roundDelay)
 
 		// get current round message
 		message, err := cache.GetDiscordSession().ChannelMessage(g.channelID, g.currentRoundMessageId)
@@ -713,6 +797,18 @@ func (g *multiBiasGame) processMultiGame() {
 			}
 		}
 
+		// the bots own reactions are counted, so a round nobody voted in has at most one of each
+		if leftCount <= 1 && rightCount <= 1 {
+			g.roundsWithoutVotes++
+		} else {
+			g.roundsWithoutVotes = 0
+		}
+		if g.roundsWithoutVotes >= getBiasGameConfig().ForGuild(utils.GetGuildIDFromChannel(g.channelID)).MultiInactiveRounds {
+			g.endAbandonedGame()
+			utils.SendMessage(g.channelID, "biasgame.game.multi-timed-out")
+			return
+		}
+
 		winnerIndex := 0
 		loserIndex := 0
 		randomWin := false
@@ -771,13 +867,27 @@ func (g *multiBiasGame) processMultiGame() {
 	g.sendWinnerMessage()
 
 	// record game stats
+	pendingStatsRecords.Add(1)
 	go recordMultiGamesStats(g)
 
 	// delete multi game from current multi games
-	for i, game := range currentMultiPlayerGames {
-		if game.currentRoundMessageId == g.currentRoundMessageId {
-			currentMultiPlayerGames = append(currentMultiPlayerGames[:i], currentMultiPlayerGames[i+1:]...)
-		}
+	runningGames.removeMultiGame(g)
+	deleteGameSnapshot(g.snapshotKey())
+}
+
+// endAbandonedGame ends a game nobody is voting in anymore. the round message is deleted and
+//  the rounds that were played are saved as an incomplete game if the config asks for it
+func (g *multiBiasGame) endAbandonedGame() {
+	runningGames.removeMultiGame(g)
+	deleteGameSnapshot(g.snapshotKey())
+
+	if g.lastRoundMessage != nil {
+		go cache.GetDiscordSession().ChannelMessageDelete(g.lastRoundMessage.ChannelID, g.lastRoundMessage.ID)
+	}
+
+	if getBiasGameConfig().RecordAbandonedGames && len(g.roundWinners) > 0 {
+		pendingStatsRecords.Add(1)
+		go recordMultiGamesStats(g)
 	}
 }
 
@@ -841,7 +951,7 @@ func (b *biasChoice) getRandomBiasImage(gameImageIndex *map[string]int) image.Im
 	var imageIndex int
 
 	// check if a random image for the idol has already been chosen for this game
-	//  also make sure that biasimages array contains the index. it may have been changed due to a refresh from googledrive
+	//  also make sure that biasimages array contains the index. it may have been changed due to a refresh of the images
 	if imagePos, ok := (*gameImageIndex)[b.fileName]; ok && len(b.biasImages) > imagePos {
 		imageIndex = imagePos
 	} else {
@@ -854,6 +964,51 @@ func (b *biasChoice) getRandomBiasImage(gameImageIndex *map[string]int) image.Im
 
 ///// MISC HELPER FUNCTIONS
 
+// endInactiveGames ends the single games that haven't had a vote within the inactive game timeout. never returns
+func endInactiveGames() {
+	for {
+		time.Sleep(INACTIVE_GAME_CHECK_INTERVAL)
+
+		// games aren't touched while the bot is shutting down, they will be restored
+		if !isGameReady() {
+			continue
+		}
+
+		// each game is checked on its own so a game that is busy sending a round doesn't hold up the others
+		var wg sync.WaitGroup
+		for _, game := range runningGames.getSingleGames() {
+			wg.Add(1)
+			go func(game *singleBiasGame) {
+				defer wg.Done()
+				endSingleGameIfInactive(game)
+			}(game)
+		}
+		wg.Wait()
+	}
+}
+
+// endSingleGameIfInactive ends the game if nobody voted in it for longer than the guilds timeout
+func endSingleGameIfInactive(game *singleBiasGame) {
+	defer utils.RecoverPanic("", "endSingleGameIfInactive")
+
+	var timedOut bool
+	var channelID string
+	game.update(func() {
+		channelID = game.channelID
+		timeoutMinutes := getBiasGameConfig().ForGuild(utils.GetGuildIDFromChannel(channelID)).InactiveGameTimeoutMinutes
+		if time.Since(game.lastActivity) < time.Duration(timeoutMinutes*float64(time.Minute)) {
+			return
+		}
+
+		game.endAbandonedGame()
+		timedOut = true
+	})
+
+	if timedOut {
+		utils.SendMessagef(channelID, "biasgame.game.timed-out", game.user.Mention())
+	}
+}
+
 // giveImageShadowBorder give the round image a shadow border
 func giveImageShadowBorder(img image.Image, offsetX int, offsetY int) image.Image {
 	rgba := image.NewRGBA(shadowBorder.Bounds())
@@ -861,3 +1016,20 @@ func giveImageShadowBorder(img image.Image, offsetX int, offsetY int) image.Imag
 	draw.Draw(rgba, img.Bounds().Add(image.Pt(offsetX, offsetY)), img, image.ZP, draw.Over)
 	return rgba.SubImage(rgba.Rect)
 }
+
+// getBiasGameConfig returns the biasgame section of the app config
+func getBiasGameConfig() models.BiasGameConfig {
+	return cache.GetAppConfig().BiasGame
+}
+
+// isGameConfigured checks if the drive folders the game needs are set in the config.
+//  local images only need their folder to exist
+func isGameConfigured() bool {
+	config := getBiasGameConfig()
+	if config.ImageSource == models.IMAGE_SOURCE_LOCAL {
+		_, err := os.Stat(config.LocalImagesPath)
+		return err == nil
+	}
+
+	return config.GirlsFolderID != "" && config.BoysFolderID != "" && config.MiscFolderID != ""
+}
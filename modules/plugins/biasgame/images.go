@@ -0,0 +1,214 @@
+package biasgame
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+	"sync"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/nfnt/resize"
+)
+
+// loadMiscImages handles loading other images besides the idol images.
+//  returns false if any of the images the game is drawn with could not be loaded
+func loadMiscImages() bool {
+
+	miscImages, err := cache.GetImageSource().ListMiscImages()
+	if err != nil {
+		fmt.Println("Error getting misc images: ", err.Error())
+		return false
+	}
+
+	for _, miscImage := range miscImages {
+		imageData, err := cache.GetImageSource().FetchImage(miscImage)
+		if err != nil {
+			fmt.Printf("Error getting misc image %s: %s\n", miscImage.FileName, err.Error())
+			continue
+		}
+		img, _, err := image.Decode(imageData)
+		imageData.Close()
+		if err != nil {
+			fmt.Printf("Error decoding misc image %s: %s\n", miscImage.FileName, err.Error())
+			continue
+		}
+
+		switch miscImage.FileName {
+		case "verses.png":
+			fmt.Println("loading verses image")
+
+			// resize verses image to match the bias image sizes
+			resizedImage := resize.Resize(0, IMAGE_RESIZE_HEIGHT+30, img, resize.Lanczos3)
+			versesImage = resizedImage
+
+		case "topEightBracket.png":
+
+			fmt.Println("loading verses top eight bracket image")
+			winnerBracket = img
+		case "shadow-border.png":
+
+			fmt.Println("loading shadow border image")
+			resizedImage := resize.Resize(0, IMAGE_RESIZE_HEIGHT+30, img, resize.Lanczos3)
+			shadowBorder = resizedImage
+		case "crown.png":
+
+			fmt.Println("loading crown image")
+			resizedImage := resize.Resize(IMAGE_RESIZE_HEIGHT/2, 0, img, resize.Lanczos3)
+			crown = resizedImage
+		}
+	}
+
+	// every round and winner image is drawn with these
+	requiredImages := []struct {
+		fileName string
+		image    image.Image
+	}{
+		{"verses.png", versesImage},
+		{"topEightBracket.png", winnerBracket},
+		{"shadow-border.png", shadowBorder},
+		{"crown.png", crown},
+	}
+
+	var missingImages []string
+	for _, requiredImage := range requiredImages {
+		if requiredImage.image == nil {
+			missingImages = append(missingImages, requiredImage.fileName)
+		}
+	}
+	if len(missingImages) > 0 {
+		fmt.Printf("Biasgame is turned off. the misc images %s could not be loaded, check they are in the misc folder of biasgame.image_source\n", strings.Join(missingImages, ", "))
+		return false
+	}
+
+	// append crown to top eight
+	bracketImage := image.NewRGBA(winnerBracket.Bounds())
+	draw.Draw(bracketImage, winnerBracket.Bounds(), winnerBracket, image.Point{0, 0}, draw.Src)
+	draw.Draw(bracketImage, crown.Bounds().Add(image.Pt(230, 5)), crown, image.ZP, draw.Over)
+	winnerBracket = bracketImage.SubImage(bracketImage.Rect)
+
+	return true
+}
+
+// refreshBiasChoices refreshes the list of bias choices and returns the amount of idols loaded.
+//   initially called when bot starts but is also safe to call while bot is running if necessary.
+//   if no idols could be loaded the current choices are kept and zero is returned
+func refreshBiasChoices() int {
+
+	// get idol images from the image source
+	allImages, err := cache.GetImageSource().ListIdolImages()
+	if err != nil {
+		fmt.Println("Error getting idol images: ", err.Error())
+		return 0
+	}
+
+	if len(allImages) > 0 {
+		var wg sync.WaitGroup
+		mux := new(sync.Mutex)
+
+		// set up temp array and load that first to avoid issues with a user startin a game while the biases are being refreshed
+		var tempAllBiases []*biasChoice
+
+		fmt.Println("Loading Files:", len(allImages))
+		for _, sourceImage := range allImages {
+			// if !strings.HasPrefix(file.Name, "P") && !strings.HasPrefix(file.Name, "T") && !strings.HasPrefix(file.Name, "C") && !strings.HasPrefix(file.Name, "B") {
+			// 	continue
+			// }
+			wg.Add(1)
+
+			go func(sourceImage models.SourceImage) {
+				defer wg.Done()
+
+				newBiasChoice, err := makeBiasChoiceFromImage(sourceImage)
+				if err != nil {
+					return
+				}
+
+				mux.Lock()
+				defer mux.Unlock()
+
+				// if the bias already exists, then just add this picture to the image array for the idol
+				for _, currentBias := range tempAllBiases {
+					if currentBias.fileName == newBiasChoice.fileName {
+						currentBias.biasImages = append(currentBias.biasImages, newBiasChoice.biasImages[0])
+						return
+					}
+				}
+
+				tempAllBiases = append(tempAllBiases, newBiasChoice)
+			}(sourceImage)
+		}
+		wg.Wait()
+
+		fmt.Println("Amount of idols loaded: ", len(tempAllBiases))
+		if len(tempAllBiases) > 0 {
+			setAllBiasChoices(tempAllBiases)
+		}
+
+		return len(tempAllBiases)
+	}
+
+	fmt.Println("No bias files found.")
+	return 0
+}
+
+// makeBiasChoiceFromImage fetches the image and makes a bias choice of the idol with it
+func makeBiasChoiceFromImage(sourceImage models.SourceImage) (*biasChoice, error) {
+	imageData, err := cache.GetImageSource().FetchImage(sourceImage)
+	if err != nil {
+		fmt.Println("get error: ", err.Error())
+		return nil, err
+	}
+	defer imageData.Close()
+
+	// decode image
+	img, imgErr := utils.DecodeImage(imageData)
+	if imgErr != nil {
+		fmt.Printf("error decoding image %s:\n %s", sourceImage.FileName, imgErr)
+		return nil, imgErr
+	}
+
+	resizedImage := resize.Resize(0, IMAGE_RESIZE_HEIGHT, img, resize.Lanczos3)
+
+	newBiasChoice := &biasChoice{
+		fileName:   sourceImage.FileName,
+		groupName:  sourceImage.GroupName,
+		biasName:   sourceImage.Name,
+		biasImages: []image.Image{resizedImage},
+		gender:     sourceImage.Gender,
+	}
+
+	return newBiasChoice, nil
+}
+
+// addImageToAllBiases will take an idol image, convert it to a bias object,
+//   and add it to allBiasChoices or add a new image if the idol already exists.
+//   running games may be using the current choices, so the choices are copied instead of changed
+func addImageToAllBiases(sourceImage models.SourceImage) {
+	newBiasChoice, err := makeBiasChoiceFromImage(sourceImage)
+	if err != nil {
+		return
+	}
+
+	biasChoicesMutex.Lock()
+	defer biasChoicesMutex.Unlock()
+
+	updatedBiasChoices := make([]*biasChoice, len(allBiasChoices), len(allBiasChoices)+1)
+	copy(updatedBiasChoices, allBiasChoices)
+
+	// if the bias already exists, then just add this picture to the image array for the idol
+	for i, currentBias := range updatedBiasChoices {
+		if currentBias.fileName == newBiasChoice.fileName {
+			updatedBias := *currentBias
+			updatedBias.biasImages = append(append([]image.Image{}, currentBias.biasImages...), newBiasChoice.biasImages[0])
+			updatedBiasChoices[i] = &updatedBias
+
+			allBiasChoices = updatedBiasChoices
+			return
+		}
+	}
+
+	allBiasChoices = append(updatedBiasChoices, newBiasChoice)
+}
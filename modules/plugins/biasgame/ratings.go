@@ -0,0 +1,277 @@
+package biasgame
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/modules/commands/args"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	ELO_STARTING_RATING = 1500
+	ELO_K_FACTOR        = 32 // most a rating can change in one round
+	MAX_RATINGS_SHOWN   = 100
+)
+
+// ratings are read, changed, and saved back, so only one game or rebuild may change them at a time.
+//  games are also saved while it is held, so a rebuild counts every game saved before it and no game after it
+var ratingsMutex sync.Mutex
+
+// ratingScope is who a rating belongs to, scope id is empty for global ratings
+type ratingScope struct {
+	scope   string
+	scopeID string
+}
+
+// ratingTable holds ratings while rounds are applied to them, keyed by the rating key
+type ratingTable map[string]*models.IdolRatingEntry
+
+// gameRatingScopes returns the scopes a game changes the ratings of.
+//  multi games have no single user so only change the global and guild ratings
+func gameRatingScopes(game models.BiasGameEntry) []ratingScope {
+	scopes := []ratingScope{{scope: models.RATING_SCOPE_GLOBAL}}
+	if game.GuildID != "" {
+		scopes = append(scopes, ratingScope{scope: models.RATING_SCOPE_GUILD, scopeID: game.GuildID})
+	}
+	if game.GameType == "single" && game.UserID != "" {
+		scopes = append(scopes, ratingScope{scope: models.RATING_SCOPE_USER, scopeID: game.UserID})
+	}
+
+	return scopes
+}
+
+// get returns the rating of the idol in the scope, idols without a rating start at the starting rating
+func (t ratingTable) get(scope ratingScope, idol models.BiasEntry) *models.IdolRatingEntry {
+	rating := models.IdolRatingEntry{
+		Scope:     scope.scope,
+		ScopeID:   scope.scopeID,
+		GroupName: idol.GroupName,
+		Name:      idol.Name,
+		Gender:    idol.Gender,
+		Rating:    ELO_STARTING_RATING,
+	}
+
+	if savedRating, ok := t[rating.Key()]; ok {
+		return savedRating
+	}
+
+	t[rating.Key()] = &rating
+	return &rating
+}
+
+// applyGame updates the ratings of every scope of the game with each round the game had
+func (t ratingTable) applyGame(game models.BiasGameEntry) {
+	for _, scope := range gameRatingScopes(game) {
+		for i := 0; i < len(game.RoundWinners) && i < len(game.RoundLosers); i++ {
+			winner := t.get(scope, game.RoundWinners[i])
+			loser := t.get(scope, game.RoundLosers[i])
+
+			applyEloRound(winner, loser)
+			winner.LastUpdated = game.CreatedAt
+			loser.LastUpdated = game.CreatedAt
+		}
+	}
+}
+
+// entries returns the ratings in the table
+func (t ratingTable) entries() []models.IdolRatingEntry {
+	var ratings []models.IdolRatingEntry
+	for _, rating := range t {
+		ratings = append(ratings, *rating)
+	}
+
+	return ratings
+}
+
+// applyEloRound moves rating points from the loser to the winner.
+//  beating a higher rated idol is worth more than beating a lower rated one
+func applyEloRound(winner *models.IdolRatingEntry, loser *models.IdolRatingEntry) {
+	expectedScore := 1 / (1 + math.Pow(10, (loser.Rating-winner.Rating)/400))
+	change := ELO_K_FACTOR * (1 - expectedScore)
+
+	winner.Rating += change
+	loser.Rating -= change
+	winner.Wins++
+	loser.Losses++
+}
+
+// insertRatedGame saves the finished game and applies its rounds to the saved ratings.
+//  returns an error if the game could not be saved, ratings that could not be updated are only logged
+func insertRatedGame(game *models.BiasGameEntry) error {
+	ratingsMutex.Lock()
+	defer ratingsMutex.Unlock()
+
+	game.CreatedAt = time.Now()
+	if err := cache.GetRepositories().Games.InsertGame(game); err != nil {
+		return err
+	}
+
+	if err := updateGameRatings(*game); err != nil {
+		fmt.Println("Error updating biasgame ratings: ", err.Error())
+	}
+
+	return nil
+}
+
+// updateGameRatings applies the rounds of a finished game to the saved ratings, ratingsMutex must be held
+func updateGameRatings(game models.BiasGameEntry) error {
+	// only the ratings of idols in the game are loaded
+	idols := append(append([]models.BiasEntry{}, game.RoundWinners...), game.RoundLosers...)
+
+	table := make(ratingTable)
+	for _, scope := range gameRatingScopes(game) {
+		ratings, err := cache.GetRepositories().Ratings.FindRatings(models.IdolRatingFilter{
+			Scope:   scope.scope,
+			ScopeID: scope.scopeID,
+			Idols:   idols,
+		}, 0)
+		if err != nil {
+			return err
+		}
+
+		for i := range ratings {
+			table[ratings[i].Key()] = &ratings[i]
+		}
+	}
+
+	table.applyGame(game)
+
+	return cache.GetRepositories().Ratings.SaveRatings(table.entries())
+}
+
+// rebuildRatings replaces all ratings with ratings calculated from every saved game, oldest game first.
+//  returns the amount of games the ratings were calculated from
+func rebuildRatings() (int, error) {
+	ratingsMutex.Lock()
+	defer ratingsMutex.Unlock()
+
+	rebuiltAt := time.Now()
+	games, err := cache.GetRepositories().Games.FindGames(models.BiasGameFilter{CreatedTo: rebuiltAt, IncludeIncomplete: true})
+	if err != nil {
+		return 0, err
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].CreatedAt.Before(games[j].CreatedAt)
+	})
+
+	table := make(ratingTable)
+	for _, game := range games {
+		table.applyGame(game)
+	}
+
+	if err := cache.GetRepositories().Ratings.DeleteRatings(); err != nil {
+		return 0, err
+	}
+	if err := cache.GetRepositories().Ratings.SaveRatings(table.entries()); err != nil {
+		return 0, err
+	}
+
+	return len(games), nil
+}
+
+// backfillRatings calculates the ratings from the saved games if there are no ratings yet,
+//  so games played before ratings existed are counted
+func backfillRatings() {
+	defer utils.RecoverPanic("", "backfillRatings")
+
+	ratings, err := cache.GetRepositories().Ratings.FindRatings(models.IdolRatingFilter{Scope: models.RATING_SCOPE_GLOBAL}, 1)
+	if err != nil {
+		fmt.Println("Error checking biasgame ratings: ", err.Error())
+		return
+	}
+	if len(ratings) > 0 {
+		return
+	}
+
+	gameCount, err := rebuildRatings()
+	if err != nil {
+		fmt.Println("Error backfilling biasgame ratings: ", err.Error())
+		return
+	}
+	if gameCount > 0 {
+		fmt.Printf("Backfilled biasgame ratings from %d games\n", gameCount)
+	}
+}
+
+// ratingsCommand displays the highest rated idols globally, in the server, or for a user
+func ratingsCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	commandArgs := args.Parse(content)
+
+	filter := models.IdolRatingFilter{Scope: models.RATING_SCOPE_GLOBAL}
+	iconURL := cache.GetDiscordSession().State.User.AvatarURL("512")
+	targetName := "Global"
+
+	filter.Gender, _ = commandArgs.KeywordIn(statsGenders)
+	if scope, ok := commandArgs.KeywordIn(statsScopes); ok {
+		if scope == "server" {
+			guild, err := utils.GetGuildFromMessage(msg)
+			if err != nil {
+				utils.SendMessage(msg.ChannelID, "biasgame.ratings.no-ratings")
+				return
+			}
+
+			filter = models.IdolRatingFilter{Scope: models.RATING_SCOPE_GUILD, ScopeID: guild.ID, Gender: filter.Gender}
+			iconURL = discordgo.EndpointGuildIcon(guild.ID, guild.Icon)
+			targetName = "Server"
+		}
+	} else if userID, ok := commandArgs.OptionalUser("user"); ok {
+		user := getMentionedUser(msg, userID)
+
+		filter = models.IdolRatingFilter{Scope: models.RATING_SCOPE_USER, ScopeID: user.ID, Gender: filter.Gender}
+		iconURL = user.AvatarURL("512")
+		targetName = user.Username
+	}
+
+	if err := commandArgs.Done(); err != nil {
+		args.SendError(msg, err, "biasgame ratings")
+		return
+	}
+
+	ratings, err := cache.GetRepositories().Ratings.FindRatings(filter, MAX_RATINGS_SHOWN)
+	if err != nil || len(ratings) == 0 {
+		utils.SendMessage(msg.ChannelID, "biasgame.ratings.no-ratings")
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Color: 0x0FADED, // blueish
+		Author: &discordgo.MessageEmbedAuthor{
+			Name:    fmt.Sprintf("%s - Idol Ratings", targetName),
+			IconURL: iconURL,
+		},
+	}
+
+	for i, rating := range ratings {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("#%d - %.0f", i+1, rating.Rating),
+			Value:  fmt.Sprintf("%s %s\n%d W / %d L", rating.GroupName, rating.Name, rating.Wins, rating.Losses),
+			Inline: true,
+		})
+	}
+
+	utils.SendPagedMessage(msg, embed, 12)
+}
+
+// rebuildRatingsCommand recalculates all ratings from the saved games
+func rebuildRatingsCommand(command string, content string, msg *discordgo.Message, session *discordgo.Session) {
+	message, _ := utils.SendMessage(msg.ChannelID, "biasgame.ratings.rebuilding")
+
+	gameCount, err := rebuildRatings()
+	if message != nil {
+		cache.GetDiscordSession().ChannelMessageDelete(msg.ChannelID, message.ID)
+	}
+	if err != nil {
+		utils.SendMessagef(msg.ChannelID, "biasgame.ratings.rebuild-failed", err.Error())
+		return
+	}
+
+	utils.SendMessagef(msg.ChannelID, "biasgame.ratings.rebuilt", gameCount)
+}
@@ -0,0 +1,303 @@
+package biasgame
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// snapshotKey identifies the game in storage, a user can only have one single game at a time
+func (g *singleBiasGame) snapshotKey() string {
+	return "single:" + g.user.ID
+}
+
+// snapshotKey identifies the game in storage, a channel can only have one multi game at a time
+func (g *multiBiasGame) snapshotKey() string {
+	return "multi:" + g.channelID
+}
+
+// saveSnapshot saves the state of the game so it can be restored if the bot restarts
+func (g *singleBiasGame) saveSnapshot() {
+	snapshot := &models.BiasGameSnapshotEntry{
+		GameKey:          g.snapshotKey(),
+		GameType:         "single",
+		UserID:           g.user.ID,
+		ChannelID:        g.channelID,
+		Gender:           g.gender,
+		IdolsRemaining:   g.idolsRemaining,
+		BiasQueue:        compileGameWinnersLosers(g.biasQueue),
+		RoundWinners:     compileGameWinnersLosers(g.roundWinners),
+		RoundLosers:      compileGameWinnersLosers(g.roundLosers),
+		RoundWinnerSides: g.roundWinnerSides,
+		TopEight:         compileGameWinnersLosers(g.topEight),
+		ImageIndexes:     compileImageIndexes(g.gameImageIndex),
+		StartedAt:        g.startedAt,
+		UpdatedAt:        time.Now(),
+	}
+	if g.lastRoundMessage != nil {
+		snapshot.LastRoundMessageID = g.lastRoundMessage.ID
+	}
+
+	saveGameSnapshot(snapshot)
+}
+
+// saveSnapshot saves the state of the game so it can be restored if the bot restarts
+func (g *multiBiasGame) saveSnapshot() {
+	snapshot := &models.BiasGameSnapshotEntry{
+		GameKey:            g.snapshotKey(),
+		GameType:           "multi",
+		ChannelID:          g.channelID,
+		Gender:             g.gender,
+		IdolsRemaining:     g.idolsRemaining,
+		RoundDelay:         g.roundDelay,
+		BiasQueue:          compileGameWinnersLosers(g.biasQueue),
+		RoundWinners:       compileGameWinnersLosers(g.roundWinners),
+		RoundLosers:        compileGameWinnersLosers(g.roundLosers),
+		TopEight:           compileGameWinnersLosers(g.topEight),
+		ImageIndexes:       compileImageIndexes(g.gameImageIndex),
+		LastRoundMessageID: g.currentRoundMessageId,
+		StartedAt:          g.startedAt,
+		UpdatedAt:          time.Now(),
+	}
+
+	saveGameSnapshot(snapshot)
+}
+
+// saveGameSnapshot saves the snapshot, a game that can't be saved keeps running but won't survive a restart
+func saveGameSnapshot(snapshot *models.BiasGameSnapshotEntry) {
+	err := cache.GetRepositories().Snapshots.SaveGameSnapshot(snapshot)
+	if err != nil {
+		fmt.Println("Error saving biasgame snapshot: ", err.Error())
+	}
+}
+
+// deleteGameSnapshot removes the snapshot of a game that has ended
+func deleteGameSnapshot(gameKey string) {
+	err := cache.GetRepositories().Snapshots.DeleteGameSnapshot(gameKey)
+	if err != nil {
+		fmt.Println("Error deleting biasgame snapshot: ", err.Error())
+	}
+}
+
+// permanentRestoreError is a snapshot that can never be restored, so it's deleted and the players are told.
+//  snapshots that fail for other reasons, like discord being unreachable, are kept for the next start
+type permanentRestoreError struct {
+	error
+}
+
+// restoreGames restores the games that were running when the bot stopped, the idols must already be loaded.
+//  single games wait for votes on their last round again. the restored multi games are returned,
+//  their rounds can't continue until the game is ready
+func restoreGames() []*multiBiasGame {
+	snapshots, err := cache.GetRepositories().Snapshots.FindGameSnapshots()
+	if err != nil {
+		fmt.Println("Error loading biasgame snapshots: ", err.Error())
+		return nil
+	}
+
+	var multiGames []*multiBiasGame
+	restored := 0
+	for _, snapshot := range snapshots {
+		switch snapshot.GameType {
+		case "single":
+			err = restoreSingleGame(snapshot)
+		case "multi":
+			var game *multiBiasGame
+			game, err = restoreMultiGame(snapshot)
+			if err == nil {
+				multiGames = append(multiGames, game)
+			}
+		default:
+			err = permanentRestoreError{fmt.Errorf("unknown game type %s", snapshot.GameType)}
+		}
+
+		if _, permanent := err.(permanentRestoreError); permanent {
+			fmt.Printf("Could not restore biasgame %s: %s\n", snapshot.GameKey, err.Error())
+			deleteGameSnapshot(snapshot.GameKey)
+			utils.SendMessage(snapshot.ChannelID, "biasgame.game.restore-failed")
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Could not restore biasgame %s, keeping it for the next start: %s\n", snapshot.GameKey, err.Error())
+			continue
+		}
+		restored++
+	}
+
+	if len(snapshots) > 0 {
+		fmt.Printf("Restored %d of %d biasgames\n", restored, len(snapshots))
+	}
+
+	return multiGames
+}
+
+// restoreSingleGame adds the game of the snapshot to the running games.
+//  if the last round message is gone the round is sent again
+func restoreSingleGame(snapshot models.BiasGameSnapshotEntry) error {
+	user, err := cache.GetDiscordSession().User(snapshot.UserID)
+	if err != nil {
+		if restErr, ok := err.(*discordgo.RESTError); ok && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownUser {
+			return permanentRestoreError{err}
+		}
+		return err
+	}
+
+	game := &singleBiasGame{
+		user:             user,
+		channelID:        snapshot.ChannelID,
+		idolsRemaining:   snapshot.IdolsRemaining,
+		gender:           snapshot.Gender,
+		startedAt:        snapshot.StartedAt,
+		lastActivity:     time.Now(),
+		roundWinnerSides: snapshot.RoundWinnerSides,
+		gameImageIndex:   restoreImageIndexes(snapshot.ImageIndexes),
+	}
+
+	restorer := newBiasChoiceRestorer()
+	game.biasQueue = restorer.restore(snapshot.BiasQueue)
+	game.roundWinners = restorer.restore(snapshot.RoundWinners)
+	game.roundLosers = restorer.restore(snapshot.RoundLosers)
+	game.topEight = restorer.restore(snapshot.TopEight)
+	if restorer.err != nil {
+		return restorer.err
+	}
+	if len(game.biasQueue) < 2 {
+		return permanentRestoreError{fmt.Errorf("game has %d idols left", len(game.biasQueue))}
+	}
+
+	if _, added := runningGames.addSingleGame(game); !added {
+		return fmt.Errorf("user already has a running game")
+	}
+
+	// reattach to the last round so votes on it still count
+	lastRoundMessage := getRoundMessage(snapshot.ChannelID, snapshot.LastRoundMessageID)
+	if lastRoundMessage == nil {
+		game.sendBiasGameRound()
+		return nil
+	}
+
+	game.update(func() {
+		game.lastRoundMessage = lastRoundMessage
+		game.readyForReaction = true
+	})
+
+	return nil
+}
+
+// restoreMultiGame creates the game of the snapshot and adds it to the running games.
+//  if the last round message still exists the game will count its votes instead of sending a new round
+func restoreMultiGame(snapshot models.BiasGameSnapshotEntry) (*multiBiasGame, error) {
+	game := &multiBiasGame{
+		channelID:      snapshot.ChannelID,
+		roundDelay:     snapshot.RoundDelay,
+		idolsRemaining: snapshot.IdolsRemaining,
+		gender:         snapshot.Gender,
+		startedAt:      snapshot.StartedAt,
+		gameImageIndex: restoreImageIndexes(snapshot.ImageIndexes),
+	}
+
+	restorer := newBiasChoiceRestorer()
+	game.biasQueue = restorer.restore(snapshot.BiasQueue)
+	game.roundWinners = restorer.restore(snapshot.RoundWinners)
+	game.roundLosers = restorer.restore(snapshot.RoundLosers)
+	game.topEight = restorer.restore(snapshot.TopEight)
+	if restorer.err != nil {
+		return nil, restorer.err
+	}
+	if len(game.biasQueue) < 2 {
+		return nil, permanentRestoreError{fmt.Errorf("game has %d idols left", len(game.biasQueue))}
+	}
+
+	game.lastRoundMessage = getRoundMessage(snapshot.ChannelID, snapshot.LastRoundMessageID)
+	if game.lastRoundMessage != nil {
+		game.currentRoundMessageId = game.lastRoundMessage.ID
+		game.resumeRound = true
+	}
+
+	if !runningGames.addMultiGame(game) {
+		return nil, fmt.Errorf("channel already has a running multi game")
+	}
+
+	return game, nil
+}
+
+// processRestoredMultiGame runs the rounds of a restored multi game. unlike a game started by a command
+//  it doesn't run in a command handler, so a panic in a round has to be recovered here
+func processRestoredMultiGame(game *multiBiasGame) {
+	defer utils.RecoverPanic(game.channelID, "restored multi game")
+
+	game.processMultiGame()
+}
+
+// getRoundMessage returns the round message, or nil if it was deleted or never sent
+func getRoundMessage(channelID string, messageID string) *discordgo.Message {
+	if messageID == "" {
+		return nil
+	}
+
+	message, err := cache.GetDiscordSession().ChannelMessage(channelID, messageID)
+	if err != nil {
+		return nil
+	}
+
+	return message
+}
+
+// biasChoiceRestorer finds the loaded bias choices of saved idols. the first idol that can't be found is kept as err,
+//  which happens if an idol was removed from the image source while the game was saved. games are only restored
+//  once idols have loaded, so a missing idol won't come back and the error is permanent
+type biasChoiceRestorer struct {
+	biasChoices map[models.BiasEntry]*biasChoice
+	err         error
+}
+
+func newBiasChoiceRestorer() *biasChoiceRestorer {
+	restorer := &biasChoiceRestorer{biasChoices: make(map[models.BiasEntry]*biasChoice)}
+	for _, bias := range getAllBiasChoices() {
+		restorer.biasChoices[models.BiasEntry{Name: bias.biasName, GroupName: bias.groupName, Gender: bias.gender}] = bias
+	}
+
+	return restorer
+}
+
+// restore returns the bias choices of the idols
+func (r *biasChoiceRestorer) restore(biasEntries []models.BiasEntry) []*biasChoice {
+	var biases []*biasChoice
+	for _, biasEntry := range biasEntries {
+		bias, ok := r.biasChoices[biasEntry]
+		if !ok {
+			if r.err == nil {
+				r.err = permanentRestoreError{fmt.Errorf("idol %s %s is no longer in the game", biasEntry.GroupName, biasEntry.Name)}
+			}
+			continue
+		}
+
+		biases = append(biases, bias)
+	}
+
+	return biases
+}
+
+// compileImageIndexes converts the images chosen for a game into a list that can be saved
+func compileImageIndexes(gameImageIndex map[string]int) []models.BiasGameImageIndex {
+	var imageIndexes []models.BiasGameImageIndex
+	for fileName, index := range gameImageIndex {
+		imageIndexes = append(imageIndexes, models.BiasGameImageIndex{FileName: fileName, Index: index})
+	}
+
+	return imageIndexes
+}
+
+// restoreImageIndexes converts saved image indexes back into the map games use
+func restoreImageIndexes(imageIndexes []models.BiasGameImageIndex) map[string]int {
+	gameImageIndex := make(map[string]int)
+	for _, imageIndex := range imageIndexes {
+		gameImageIndex[imageIndex.FileName] = imageIndex.Index
+	}
+
+	return gameImageIndex
+}
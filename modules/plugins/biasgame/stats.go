@@ -1,92 +1,226 @@
 package biasgame
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Snakeyesz/snek-bot/cache"
 	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/modules/commands/args"
 	"github.com/Snakeyesz/snek-bot/utils"
 	"github.com/bwmarrin/discordgo"
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
 )
 
-// displayBiasGameStats will display stats for the bias game based on the stats message
-func displayBiasGameStats(msg *discordgo.Message, statsMessage string) {
-	results, iconURL, targetName := getStatsResults(msg, statsMessage)
+// game stats that are still being saved, shutdown waits on these so finished games aren't lost
+var pendingStatsRecords sync.WaitGroup
+
+// statsOptions are the filters and grouping asked for in a stats command
+type statsOptions struct {
+	roundsWon  bool
+	roundsLost bool
+	byGroup    bool
+	multi      bool
+	incomplete bool            // include games that were abandoned before they had a winner
+	scope      string          // user, server, or global
+	user       *discordgo.User // user to show stats for when scope is user
+	gender     string          // gender of the game winner, empty for any
+	from       time.Time       // games finished at or after this time, zero for any
+	to         time.Time       // games finished before this time, zero for any
+	timeRange  string          // the time range as shown to the user, empty for all time
+}
 
-	// check if any stats were returned
-	totalGames, err := results.Count()
-	if err != nil || totalGames == 0 {
-		utils.SendMessage(msg.ChannelID, "biasgame.stats.no-stats")
-		return
+var statsScopes = map[string]string{
+	"server": "server",
+	"global": "global",
+}
+
+const (
+	STATS_DATE_FORMAT    = "2006-01-02"
+	MAX_STATS_RANGE_DAYS = 3650
+	MAX_STATS_RESULTS    = 250 // most counted idols or groups shown in stats
+	MAX_RANKINGS         = 50
+)
+
+var statsGenders = map[string]string{
+	"boy":   "boy",
+	"boys":  "boy",
+	"girl":  "girl",
+	"girls": "girl",
+}
+
+// parseStatsOptions reads the stats options from the command content.
+//  options can be given in any order as words or as flags, "rounds won" is the same as --rounds-won
+func parseStatsOptions(msg *discordgo.Message, content string) (statsOptions, error) {
+	commandArgs := args.Parse(content)
+
+	options := statsOptions{
+		roundsWon:  commandArgs.Keyword("rounds won"),
+		roundsLost: commandArgs.Keyword("rounds lost"),
+		byGroup:    commandArgs.Keyword("group"),
+		multi:      commandArgs.Keyword("multi"),
+		incomplete: commandArgs.Keyword("incomplete"),
+		scope:      "user",
+		user:       msg.Author,
 	}
+	options.gender, _ = commandArgs.KeywordIn(statsGenders)
 
-	statsTitle := ""
-	countsHeader := ""
+	// read the time range before the user, otherwise the range words would be taken as a missing user mention
+	location := utils.GetGuildLocation(utils.GetGuildIDFromMessage(msg))
+	var err error
+	options.from, options.to, options.timeRange, err = parseStatsTimeRange(commandArgs, location, time.Now())
+	if err != nil {
+		return options, err
+	}
 
-	// loop through the results and compile a map of [biasgroup biasname]number of occurences
-	items := results.Iter()
-	biasCounts := make(map[string]int)
-	game := models.BiasGameEntry{}
-	for items.Next(&game) {
-		groupAndName := ""
+	if scope, ok := commandArgs.KeywordIn(statsScopes); ok {
+		options.scope = scope
+	} else if userID, ok := commandArgs.OptionalUser("user"); ok {
+		options.user = getMentionedUser(msg, userID)
+	}
 
-		if strings.Contains(statsMessage, "rounds won") {
+	return options, commandArgs.Done()
+}
 
-			// round winners
-			for _, rWinner := range game.RoundWinners {
+// parseStatsTimeRange reads the time range the stats are limited to, in the time zone of the guild.
+//  ranges can be today, yesterday, this week, this month, this year, last N days, or from and/or to dates.
+//  a zero from or to means the range has no start or end
+func parseStatsTimeRange(commandArgs *args.Args, location *time.Location, now time.Time) (time.Time, time.Time, string, error) {
+	now = now.In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	switch {
+	case commandArgs.Keyword("today"):
+		return today, today.AddDate(0, 0, 1), "today", nil
+	case commandArgs.Keyword("yesterday"):
+		return today.AddDate(0, 0, -1), today, "yesterday", nil
+	case commandArgs.Keyword("this week"):
+		// weeks start on monday
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -daysSinceMonday), time.Time{}, "this week", nil
+	case commandArgs.Keyword("this month"):
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location), time.Time{}, "this month", nil
+	case commandArgs.Keyword("this year"):
+		return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, location), time.Time{}, "this year", nil
+	}
 
-				if strings.Contains(statsMessage, "group") {
-					statsTitle = "Rounds Won in Bias Game by Group"
-					groupAndName = fmt.Sprintf("%s", rWinner.GroupName)
-				} else {
-					statsTitle = "Rounds Won in Bias Game"
-					groupAndName = fmt.Sprintf("**%s** %s", rWinner.GroupName, rWinner.Name)
-				}
-				biasCounts[groupAndName] += 1
-			}
+	// last N days includes today
+	if days, ok := commandArgs.KeywordValue("last", "days"); ok {
+		amount, err := strconv.Atoi(days)
+		if err != nil || amount < 1 || amount > MAX_STATS_RANGE_DAYS {
+			return time.Time{}, time.Time{}, "", &args.Error{Key: "biasgame.stats.invalid-days", Params: []interface{}{days, MAX_STATS_RANGE_DAYS}}
+		}
 
-			countsHeader = "Rounds Won"
+		return today.AddDate(0, 0, 1-amount), time.Time{}, fmt.Sprintf("last %d days", amount), nil
+	}
 
-		} else if strings.Contains(statsMessage, "rounds lost") {
+	var from, to time.Time
+	var labels []string
 
-			// round losers
-			for _, rLoser := range game.RoundLosers {
+	if fromDate, ok := commandArgs.KeywordValue("from", ""); ok {
+		date, err := time.ParseInLocation(STATS_DATE_FORMAT, fromDate, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", &args.Error{Key: "biasgame.stats.invalid-date", Params: []interface{}{fromDate}}
+		}
 
-				if strings.Contains(statsMessage, "group") {
-					statsTitle = "Rounds Lost in Bias Game by Group"
-					groupAndName = fmt.Sprintf("%s", rLoser.GroupName)
-				} else {
-					statsTitle = "Rounds Lost in Bias Game"
-					groupAndName = fmt.Sprintf("**%s** %s", rLoser.GroupName, rLoser.Name)
-				}
-				biasCounts[groupAndName] += 1
-			}
+		from = date
+		labels = append(labels, "from "+fromDate)
+	}
 
-			statsTitle = "Rounds Lost in Bias Game"
-			countsHeader = "Rounds Lost"
-		} else {
+	// the to date is included in the range
+	if toDate, ok := commandArgs.KeywordValue("to", ""); ok {
+		date, err := time.ParseInLocation(STATS_DATE_FORMAT, toDate, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, "", &args.Error{Key: "biasgame.stats.invalid-date", Params: []interface{}{toDate}}
+		}
 
-			// game winners
-			if strings.Contains(statsMessage, "group") {
-				statsTitle = "Bias Game Winners by Group"
-				groupAndName = fmt.Sprintf("%s", game.GameWinner.GroupName)
-			} else {
-				statsTitle = "Bias Game Winners"
-				groupAndName = fmt.Sprintf("**%s** %s", game.GameWinner.GroupName, game.GameWinner.Name)
-			}
+		to = date.AddDate(0, 0, 1)
+		labels = append(labels, "to "+toDate)
+	}
 
-			biasCounts[groupAndName] += 1
-			countsHeader = "Games Won"
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return time.Time{}, time.Time{}, "", &args.Error{Key: "biasgame.stats.invalid-date-range"}
+	}
 
+	return from, to, strings.Join(labels, " "), nil
+}
+
+// getMentionedUser finds a user mentioned in the message
+func getMentionedUser(msg *discordgo.Message, userID string) *discordgo.User {
+	for _, user := range msg.Mentions {
+		if user.ID == userID {
+			return user
+		}
+	}
+
+	user, err := cache.GetDiscordSession().User(userID)
+	if err != nil {
+		return msg.Author
+	}
+
+	return user
+}
+
+// displayBiasGameStats will display stats for the bias game based on the stats options
+func displayBiasGameStats(msg *discordgo.Message, options statsOptions) {
+	filter, iconURL, targetName, ok := getStatsFilter(msg, options)
+	if !ok {
+		utils.SendMessage(msg.ChannelID, "settings.guild-only")
+		return
+	}
+	query := models.BiasGameStatsQuery{
+		Filter:  filter,
+		Count:   models.COUNT_GAME_WINNERS,
+		ByGroup: options.byGroup,
+		Limit:   MAX_STATS_RESULTS,
+	}
+
+	statsTitle := "Bias Game Winners"
+	countsHeader := "Games Won"
+	if options.roundsWon {
+		query.Count = models.COUNT_ROUND_WINNERS
+		statsTitle = "Rounds Won in Bias Game"
+		countsHeader = "Rounds Won"
+	} else if options.roundsLost {
+		query.Count = models.COUNT_ROUND_LOSERS
+		statsTitle = "Rounds Lost in Bias Game"
+		countsHeader = "Rounds Lost"
+	}
+	if options.byGroup {
+		statsTitle += " by Group"
+	}
+
+	// check if any stats were returned
+	stats, err := gameStats.countBiases(query)
+	if err != nil || stats.TotalGames == 0 {
+		utils.SendMessage(msg.ChannelID, "biasgame.stats.no-stats")
+		return
+	}
+
+	// compile a map of [biasgroup biasname]number of occurences
+	biasCounts := make(map[string]int)
+	for _, biasCount := range stats.Counts {
+		if options.byGroup {
+			biasCounts[biasCount.GroupName] = biasCount.Count
+		} else {
+			biasCounts[fmt.Sprintf("**%s** %s", biasCount.GroupName, biasCount.Name)] = biasCount.Count
 		}
 	}
 
-	// add total games to the stats header message
-	statsTitle = fmt.Sprintf("%s (%d games)", statsTitle, totalGames)
+	// add total games and the time range to the stats header message
+	gamesLabel := fmt.Sprintf("%d games", stats.TotalGames)
+	if options.incomplete {
+		gamesLabel += " with incomplete"
+	}
+	if options.timeRange != "" {
+		statsTitle = fmt.Sprintf("%s (%s, %s)", statsTitle, gamesLabel, options.timeRange)
+	} else {
+		statsTitle = fmt.Sprintf("%s (%s)", statsTitle, gamesLabel)
+	}
 
 	sendStatsMessage(msg, statsTitle, countsHeader, biasCounts, iconURL, targetName)
 }
@@ -95,15 +229,16 @@ func displayBiasGameStats(msg *discordgo.Message, statsMessage string) {
 func listIdolsInGame(msg *discordgo.Message) {
 
 	// create map of idols and there group
+	biasChoices := getAllBiasChoices()
 	groupIdolMap := make(map[string][]string)
-	for _, bias := range allBiasChoices {
+	for _, bias := range biasChoices {
 		groupIdolMap[bias.groupName] = append(groupIdolMap[bias.groupName], bias.biasName)
 	}
 
 	embed := &discordgo.MessageEmbed{
 		Color: 0x0FADED, // blueish
 		Author: &discordgo.MessageEmbedAuthor{
-			Name: fmt.Sprintf("All Idols Available In Bias Game (%d total)", len(allBiasChoices)),
+			Name: fmt.Sprintf("All Idols Available In Bias Game (%d total)", len(biasChoices)),
 		},
 	}
 
@@ -130,70 +265,16 @@ func listIdolsInGame(msg *discordgo.Message) {
 	utils.SendPagedMessage(msg, embed, 10)
 }
 
-// listIdolsInGame will list all idols that can show up in the biasgame
+// showSingleGameRankings will list the users who have finished the most single player games
 func showSingleGameRankings(msg *discordgo.Message) {
-
-	fmt.Println("Getting Rankings...")
-	type rankingStruct struct {
-		userId           string
-		amountOfGames    int
-		idolWithMostWins string
-		userName         string
-	}
-
-	results := utils.MongoDBSearch(models.BiasGameTable, bson.M{"gametype": "single"})
+	userRankings, err := gameStats.rankUsers(models.BiasGameFilter{GameType: "single"}, MAX_RANKINGS)
 
 	// check if any stats were returned
-	totalGames, err := results.Count()
-	if err != nil || totalGames == 0 {
+	if err != nil || len(userRankings) == 0 {
 		utils.SendMessage(msg.ChannelID, "biasgame.stats.no-stats")
 		return
 	}
 
-	fmt.Println("Result Count: ", totalGames)
-
-	// loop through the results and compile a map of userids => gameWinner group+name
-	items := results.Iter()
-	rankingsInfo := make(map[string][]string)
-	game := models.BiasGameEntry{}
-	for items.Next(&game) {
-		rankingsInfo[game.UserID] = append(rankingsInfo[game.UserID], fmt.Sprintf("%s %s", game.GameWinner.GroupName, game.GameWinner.Name))
-	}
-
-	// get the amount of wins and idol with most wins for each user
-	userRankings := []*rankingStruct{}
-	for userId, gameWinners := range rankingsInfo {
-		userRankingInfo := &rankingStruct{
-			userId:        userId,
-			amountOfGames: len(gameWinners),
-		}
-
-		// get idol with most wins for this user
-		idolCountMap := make(map[string]int)
-		highestWins := 0
-		for _, idol := range gameWinners {
-			idolCountMap[idol]++
-		}
-		for idol, amountOfGames := range idolCountMap {
-			if amountOfGames > highestWins {
-				highestWins = amountOfGames
-				userRankingInfo.idolWithMostWins = idol
-			}
-		}
-
-		userRankings = append(userRankings, userRankingInfo)
-	}
-
-	// sort rankings by most wins and get top 50
-	// sort fields by group name
-	sort.Slice(userRankings, func(i, j int) bool {
-		return userRankings[i].amountOfGames > userRankings[j].amountOfGames
-	})
-
-	if len(userRankings) > 50 {
-		userRankings = userRankings[:50]
-	}
-
 	embed := &discordgo.MessageEmbed{
 		Color: 0x0FADED, // blueish
 		Author: &discordgo.MessageEmbedAuthor{
@@ -207,7 +288,7 @@ func showSingleGameRankings(msg *discordgo.Message) {
 	for i, userRankingInfo := range userRankings {
 
 		userName := "*Unknown User*"
-		user, err := cache.GetDiscordSession().User(userRankingInfo.userId)
+		user, err := cache.GetDiscordSession().User(userRankingInfo.UserID)
 		if err == nil {
 			userName = user.Username
 		}
@@ -219,12 +300,12 @@ func showSingleGameRankings(msg *discordgo.Message) {
 		})
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "Total Games",
-			Value:  fmt.Sprintf("%d", userRankingInfo.amountOfGames),
+			Value:  fmt.Sprintf("%d", userRankingInfo.TotalGames),
 			Inline: true,
 		})
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "Most Winning Idol",
-			Value:  userRankingInfo.idolWithMostWins,
+			Value:  fmt.Sprintf("%s %s", userRankingInfo.TopIdol.GroupName, userRankingInfo.TopIdol.Name),
 			Inline: true,
 		})
 	}
@@ -233,7 +314,7 @@ func showSingleGameRankings(msg *discordgo.Message) {
 }
 
 // displayCurrentGameStats will list the rounds and round winners of a currently running game
-func displayCurrentGameStats(msg *discordgo.Message) {
+func displayCurrentGameStats(msg *discordgo.Message, userPlayingGame *discordgo.User) {
 
 	blankField := &discordgo.MessageEmbedField{
 		Name:   ZERO_WIDTH_SPACE,
@@ -241,15 +322,15 @@ func displayCurrentGameStats(msg *discordgo.Message) {
 		Inline: true,
 	}
 
-	// find currently running game for the user or a mention if one exists
-	userPlayingGame := msg.Author
-	if len(msg.Mentions) > 0 {
-		userPlayingGame = msg.Mentions[0]
+	game, ok := runningGames.getSingleGame(userPlayingGame.ID)
+	if !ok {
+		utils.SendMessage(msg.ChannelID, "biasgame.current.no-running-game")
+		return
 	}
 
-	if game, ok := currentSinglePlayerGames[userPlayingGame.ID]; ok {
-
-		embed := &discordgo.MessageEmbed{
+	var embed *discordgo.MessageEmbed
+	running := game.update(func() {
+		embed = &discordgo.MessageEmbed{
 			Color: 0x0FADED, // blueish
 			Author: &discordgo.MessageEmbedAuthor{
 				Name: fmt.Sprintf("%s - Current Game Info\n", userPlayingGame.Username),
@@ -293,18 +374,26 @@ func displayCurrentGameStats(msg *discordgo.Message) {
 		} else if len(embed.Fields)%3 == 2 {
 			embed.Fields = append(embed.Fields, blankField)
 		}
-
-		utils.SendPagedMessage(msg, embed, 12)
-	} else {
+	})
+	if !running {
 		utils.SendMessage(msg.ChannelID, "biasgame.current.no-running-game")
+		return
 	}
+
+	utils.SendPagedMessage(msg, embed, 12)
 }
 
 // recordSingleGamesStats will record the winner, round winners/losers, and other misc stats of a game
 func recordSingleGamesStats(game *singleBiasGame) {
+	defer pendingStatsRecords.Done()
+	defer utils.RecoverPanic("", "recordSingleGamesStats")
 
-	// get guildID from game channel
-	channel, _ := cache.GetDiscordSession().State.Channel(game.channelID)
+	// get guildID from game channel, restored games may be in a channel that isn't in the state
+	channel, err := cache.GetDiscordSession().State.Channel(game.channelID)
+	if err != nil {
+		fmt.Println("Error getting channel when recording stats")
+		return
+	}
 	guild, err := cache.GetDiscordSession().State.Guild(channel.GuildID)
 	if err != nil {
 		fmt.Println("Error getting guild when recording stats")
@@ -318,23 +407,43 @@ func recordSingleGamesStats(game *singleBiasGame) {
 		GuildID:      guild.ID,
 		GameType:     "single",
 		Gender:       game.gender,
+		Duration:     time.Since(game.startedAt),
 		RoundWinners: compileGameWinnersLosers(game.roundWinners),
 		RoundLosers:  compileGameWinnersLosers(game.roundLosers),
-		GameWinner: models.BiasEntry{
+	}
+
+	// abandoned games are saved without a winner
+	if game.gameWinnerBias != nil {
+		biasGameEntry.GameWinner = models.BiasEntry{
 			Name:      game.gameWinnerBias.biasName,
 			GroupName: game.gameWinnerBias.groupName,
 			Gender:    game.gameWinnerBias.gender,
-		},
+		}
+	} else {
+		biasGameEntry.Incomplete = true
+	}
+
+	// the game is given its finish time when it's saved
+	err = insertRatedGame(biasGameEntry)
+	if err != nil {
+		fmt.Println("Error saving biasgame stats: ", err.Error())
+		return
 	}
 
-	utils.MongoDBInsert(models.BiasGameTable, biasGameEntry)
+	gameStats.invalidate()
 }
 
 // recordSingleGamesStats will record the winner, round winners/losers, and other misc stats of a game
 func recordMultiGamesStats(game *multiBiasGame) {
+	defer pendingStatsRecords.Done()
+	defer utils.RecoverPanic("", "recordMultiGamesStats")
 
-	// get guildID from game channel
-	channel, _ := cache.GetDiscordSession().State.Channel(game.channelID)
+	// get guildID from game channel, restored games may be in a channel that isn't in the state
+	channel, err := cache.GetDiscordSession().State.Channel(game.channelID)
+	if err != nil {
+		fmt.Println("Error getting channel when recording stats")
+		return
+	}
 	guild, err := cache.GetDiscordSession().State.Guild(channel.GuildID)
 	if err != nil {
 		fmt.Println("Error getting guild when recording stats")
@@ -347,95 +456,114 @@ func recordMultiGamesStats(game *multiBiasGame) {
 		GuildID:      guild.ID,
 		GameType:     "multi",
 		Gender:       game.gender,
+		Duration:     time.Since(game.startedAt),
 		RoundWinners: compileGameWinnersLosers(game.roundWinners),
 		RoundLosers:  compileGameWinnersLosers(game.roundLosers),
-		GameWinner: models.BiasEntry{
+	}
+
+	// abandoned games are saved without a winner
+	if game.gameWinnerBias != nil {
+		biasGameEntry.GameWinner = models.BiasEntry{
 			Name:      game.gameWinnerBias.biasName,
 			GroupName: game.gameWinnerBias.groupName,
 			Gender:    game.gameWinnerBias.gender,
-		},
+		}
+	} else {
+		biasGameEntry.Incomplete = true
+	}
+
+	// the game is given its finish time when it's saved
+	err = insertRatedGame(biasGameEntry)
+	if err != nil {
+		fmt.Println("Error saving biasgame stats: ", err.Error())
+		return
 	}
 
-	utils.MongoDBInsert(models.BiasGameTable, biasGameEntry)
+	gameStats.invalidate()
+}
+
+// waitForPendingStats blocks until all game stats are saved or the context is done
+func waitForPendingStats(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		pendingStatsRecords.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		fmt.Println("Timed out waiting for biasgame stats to save")
+	}
 }
 
-// getStatsResults will get the stats results based on the stats message
-func getStatsResults(msg *discordgo.Message, statsMessage string) (*mgo.Query, string, string) {
+// getStatsFilter will get the filter of the games the stats are for, and the icon and name of who they are for.
+//  returns false if the stats are for the server but the message wasn't sent in one
+func getStatsFilter(msg *discordgo.Message, options statsOptions) (models.BiasGameFilter, string, string, bool) {
 	iconURL := ""
 	targetName := ""
-	guild, err := utils.GetGuildFromMessage(msg)
 
-	queryParams := bson.M{}
+	// server stats need the guild, multi stats are for the server unless they're global
+	var guild *discordgo.Guild
+	if options.scope == "server" || (options.multi && options.scope != "global") {
+		var err error
+		guild, err = utils.GetGuildFromMessage(msg)
+		if err != nil {
+			return models.BiasGameFilter{}, "", "", false
+		}
+	}
+
+	filter := models.BiasGameFilter{}
 
 	// filter by game type. multi/single
-	if strings.Contains(statsMessage, "multi") {
-		queryParams["gametype"] = "multi"
+	if options.multi {
+		filter.GameType = "multi"
 
 		// multi stats games can run for server or global with server as the default
-		if strings.Contains(statsMessage, "global") {
+		if options.scope == "global" {
 
 			iconURL = cache.GetDiscordSession().State.User.AvatarURL("512")
 			targetName = "Global"
 		} else {
-			queryParams["guildid"] = guild.ID
+			filter.GuildID = guild.ID
 			iconURL = discordgo.EndpointGuildIcon(guild.ID, guild.Icon)
 			targetName = "Server"
 
 		}
 	} else {
-		queryParams["gametype"] = "single"
+		filter.GameType = "single"
 
 		// user/server/global checks
-		if strings.Contains(statsMessage, "server") {
-
-			if err != nil {
-				// todo: a message here or something i guess?
-			}
-
+		if options.scope == "server" {
 			iconURL = discordgo.EndpointGuildIcon(guild.ID, guild.Icon)
 			targetName = "Server"
-			queryParams["guildid"] = guild.ID
-		} else if strings.Contains(statsMessage, "global") {
+			filter.GuildID = guild.ID
+		} else if options.scope == "global" {
 			iconURL = cache.GetDiscordSession().State.User.AvatarURL("512")
 			targetName = "Global"
 
-		} else if strings.Contains(statsMessage, "@") {
-			iconURL = msg.Mentions[0].AvatarURL("512")
-			targetName = msg.Mentions[0].Username
-
-			queryParams["userid"] = msg.Mentions[0].ID
 		} else {
-			iconURL = msg.Author.AvatarURL("512")
-			targetName = msg.Author.Username
+			iconURL = options.user.AvatarURL("512")
+			targetName = options.user.Username
 
-			queryParams["userid"] = msg.Author.ID
+			filter.UserID = options.user.ID
 		}
 
 	}
 
 	// filter by gamewinner gender
-	if strings.Contains(statsMessage, "boy") || strings.Contains(statsMessage, "boys") {
-		queryParams["gamewinner.gender"] = "boy"
-	} else if strings.Contains(statsMessage, "girl") || strings.Contains(statsMessage, "girls") {
-		queryParams["gamewinner.gender"] = "girl"
+	if options.gender != "" {
+		filter.WinnerGender = options.gender
 	}
 
-	//  Note: not sure if want to do dates. might be kinda cool. but could cause confusion due to timezone issues
-	// date checks
-	// if strings.Contains(statsMessage, "today") {
-	// 	// dateCheck := bson.NewObjectIdWithTime()
-	// 	messageTime, _ := msg.Timestamp.Parse()
-
-	// 	from := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 0, 0, 0, 0, messageTime.Location())
-	// 	to := time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 23, 59, 59, 0, messageTime.Location())
-
-	// 	fromId := bson.NewObjectIdWithTime(from)
-	// 	toId := bson.NewObjectIdWithTime(to)
+	// filter by when the game finished
+	filter.CreatedFrom = options.from
+	filter.CreatedTo = options.to
 
-	// 	queryParams["_id"] = bson.M{"$gte": fromId, "$lt": toId}
-	// }
+	// abandoned games only count toward the total games and the rounds they had
+	filter.IncludeIncomplete = options.incomplete
 
-	return utils.MongoDBSearch(models.BiasGameTable, queryParams), iconURL, targetName
+	return filter, iconURL, targetName, true
 }
 
 // complieGameStats will convert records from database into a:
@@ -0,0 +1,108 @@
+package biasgame
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+const (
+	// stats are also cleared whenever a game is saved, this only limits how long unused results are kept
+	STATS_CACHE_DURATION = time.Minute * 10
+)
+
+// gameStats backs the stats and rankings commands
+var gameStats = newStatsService(STATS_CACHE_DURATION)
+
+// statsService answers stats and rankings queries with the game repository.
+//  results are cached until a new game is saved, or the cache duration passes
+type statsService struct {
+	sync.Mutex
+	cacheDuration time.Duration
+	results       map[string]cachedStatsResult // query key => result
+
+	// bumped when a game is saved so results queried before the game aren't cached after it
+	generation int
+}
+
+type cachedStatsResult struct {
+	value    interface{}
+	cachedAt time.Time
+}
+
+func newStatsService(cacheDuration time.Duration) *statsService {
+	return &statsService{
+		cacheDuration: cacheDuration,
+		results:       make(map[string]cachedStatsResult),
+	}
+}
+
+// countBiases returns the counts of the stats query
+func (s *statsService) countBiases(query models.BiasGameStatsQuery) (models.BiasGameStats, error) {
+	value, err := s.cached(fmt.Sprintf("biases:%+v", query), func() (interface{}, error) {
+		return cache.GetRepositories().Games.CountBiases(query)
+	})
+	if err != nil {
+		return models.BiasGameStats{}, err
+	}
+
+	return value.(models.BiasGameStats), nil
+}
+
+// rankUsers returns the users with the most games that match the filter
+func (s *statsService) rankUsers(filter models.BiasGameFilter, limit int) ([]models.UserRanking, error) {
+	value, err := s.cached(fmt.Sprintf("rankings:%+v:%d", filter, limit), func() (interface{}, error) {
+		return cache.GetRepositories().Games.RankUsers(filter, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]models.UserRanking), nil
+}
+
+// invalidate clears the cached results, called when a game is saved
+func (s *statsService) invalidate() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.generation++
+	s.results = make(map[string]cachedStatsResult)
+}
+
+// cached returns the cached result of the key, or runs the query and caches its result.
+//  the query runs without the lock held so a slow query doesn't block other stats
+func (s *statsService) cached(key string, query func() (interface{}, error)) (interface{}, error) {
+	s.Lock()
+	result, ok := s.results[key]
+	generation := s.generation
+	s.Unlock()
+
+	if ok && time.Since(result.cachedAt) < s.cacheDuration {
+		return result.value, nil
+	}
+
+	value, err := query()
+	if err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if generation == s.generation {
+		s.results[key] = cachedStatsResult{value: value, cachedAt: time.Now()}
+	}
+
+	// drop expired results so queries that aren't asked again don't stay in memory
+	for cachedKey, cachedResult := range s.results {
+		if time.Since(cachedResult.cachedAt) >= s.cacheDuration {
+			delete(s.results, cachedKey)
+		}
+	}
+
+	return value, nil
+}
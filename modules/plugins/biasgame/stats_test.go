@@ -0,0 +1,82 @@
+package biasgame
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/modules/commands/args"
+)
+
+func TestParseStatsTimeRange(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data is not available: ", err)
+	}
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, location)
+	}
+
+	// sunday morning of the day clocks move forward, in utc it's already 10:00
+	springForward := time.Date(2018, time.March, 11, 10, 0, 0, 0, time.UTC)
+	// sunday night of the day clocks move back, in utc it's already monday
+	fallBack := time.Date(2018, time.November, 5, 2, 0, 0, 0, time.UTC)
+	monday := time.Date(2018, time.March, 12, 4, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		content string
+		now     time.Time
+		from    time.Time
+		to      time.Time
+		label   string
+		err     string
+	}{
+		{"", springForward, time.Time{}, time.Time{}, "", ""},
+		{"today", springForward, date(2018, time.March, 11), date(2018, time.March, 12), "today", ""},
+		{"today", fallBack, date(2018, time.November, 4), date(2018, time.November, 5), "today", ""},
+		{"yesterday", monday, date(2018, time.March, 11), date(2018, time.March, 12), "yesterday", ""},
+		{"this week", springForward, date(2018, time.March, 5), time.Time{}, "this week", ""},
+		{"this week", monday, date(2018, time.March, 12), time.Time{}, "this week", ""},
+		{"this week", fallBack, date(2018, time.October, 29), time.Time{}, "this week", ""},
+		{"this month", springForward, date(2018, time.March, 1), time.Time{}, "this month", ""},
+		{"this year", springForward, date(2018, time.January, 1), time.Time{}, "this year", ""},
+		{"last 7 days", springForward, date(2018, time.March, 5), time.Time{}, "last 7 days", ""},
+		{"last 1 days", fallBack, date(2018, time.November, 4), time.Time{}, "last 1 days", ""},
+		{"--last-days=2", monday, date(2018, time.March, 11), time.Time{}, "last 2 days", ""},
+		{"last 0 days", springForward, time.Time{}, time.Time{}, "", "biasgame.stats.invalid-days"},
+		{"last many days", springForward, time.Time{}, time.Time{}, "", "biasgame.stats.invalid-days"},
+		{"from 2018-03-01 to 2018-03-11", springForward, date(2018, time.March, 1), date(2018, time.March, 12), "from 2018-03-01 to 2018-03-11", ""},
+		{"to 2018-11-04", fallBack, time.Time{}, date(2018, time.November, 5), "to 2018-11-04", ""},
+		{"--from=2018-03-11", springForward, date(2018, time.March, 11), time.Time{}, "from 2018-03-11", ""},
+		{"from 2018-03-11 to 2018-03-11", springForward, date(2018, time.March, 11), date(2018, time.March, 12), "from 2018-03-11 to 2018-03-11", ""},
+		{"from 2018-03-11 to 2018-03-10", springForward, time.Time{}, time.Time{}, "", "biasgame.stats.invalid-date-range"},
+		{"from 2018-13-01", springForward, time.Time{}, time.Time{}, "", "biasgame.stats.invalid-date"},
+	}
+
+	for _, test := range tests {
+		commandArgs := args.Parse(test.content)
+		from, to, label, err := parseStatsTimeRange(commandArgs, location, test.now)
+
+		errKey := ""
+		if err != nil {
+			errKey = err.(*args.Error).Key
+		}
+		if errKey != test.err {
+			t.Errorf("%q at %s: expected error %q, got %q", test.content, test.now, test.err, errKey)
+			continue
+		}
+		if !from.Equal(test.from) || !to.Equal(test.to) || label != test.label {
+			t.Errorf("%q at %s: expected %s to %s %q, got %s to %s %q", test.content, test.now, test.from, test.to, test.label, from, to, label)
+		}
+		if err == nil && commandArgs.Done() != nil {
+			t.Errorf("%q: the time range was not fully read", test.content)
+		}
+	}
+
+	// days the clocks change on are 23 and 25 hours long
+	if from, to, _, _ := parseStatsTimeRange(args.Parse("today"), location, springForward); to.Sub(from) != 23*time.Hour {
+		t.Errorf("expected the day clocks move forward to be 23 hours, got %s", to.Sub(from))
+	}
+	if from, to, _, _ := parseStatsTimeRange(args.Parse("today"), location, fallBack); to.Sub(from) != 25*time.Hour {
+		t.Errorf("expected the day clocks move back to be 25 hours, got %s", to.Sub(from))
+	}
+}
@@ -9,22 +9,16 @@ import (
 	"strings"
 	"time"
 
-	"github.com/globalsign/mgo/bson"
 	"github.com/sethgrid/pester"
-	"google.golang.org/api/drive/v3"
-	"google.golang.org/api/googleapi"
 
 	"github.com/Snakeyesz/snek-bot/models"
 
 	"github.com/Snakeyesz/snek-bot/cache"
 	"github.com/Snakeyesz/snek-bot/utils"
 	"github.com/bwmarrin/discordgo"
-	"github.com/mgutz/str"
 )
 
 const (
-	IMAGE_SUGGESTION_CHANNEL = "420049316615553026"
-
 	CHECKMARK_EMOJI    = "✅"
 	X_EMOJI            = "❌"
 	QUESTIONMARK_EMOJI = "❓"
@@ -35,74 +29,64 @@ const (
 
 var suggestionQueue []*models.BiasGameSuggestionEntry
 var suggestionEmbedMessageId string // id of the embed message where suggestions are accepted/denied
-var genderFolderMap map[string]string
+var suggestionChannelID string      // channel suggestions are reviewed in, empty if suggestions are turned off
+
+// fields of the current suggestion that can be changed with the edit command
+var editableSuggestionFields = map[string]string{
+	"name":   "name",
+	"group":  "group",
+	"gender": "gender",
+	"notes":  "notes",
+}
 
+// genders an image can be suggested for
+var suggestionGenders = map[string]string{
+	"boy":  "boy",
+	"girl": "girl",
+}
+
+// initSuggestionChannel sets up the suggestion channel set in the config.
+//  suggestions are turned off if the channel isn't set or can't be found
 func initSuggestionChannel() {
+	suggestionChannelID = ""
+	suggestionEmbedMessageId = ""
+
+	channelID := getBiasGameConfig().SuggestionChannelID
+	if channelID == "" {
+		fmt.Println("Biasgame suggestions are turned off. Set biasgame.suggestion_channel_id in the config to turn them on")
+		return
+	}
+
+	if _, err := cache.GetDiscordSession().Channel(channelID); err != nil {
+		fmt.Println("Biasgame suggestions are turned off. Unable to find the suggestion channel", channelID, err.Error())
+		return
+	}
+	suggestionChannelID = channelID
 
 	// when the bot starts, delete any past bot messages from the suggestion channel and make the embed
 	var messagesToDelete []string
-	messagesInChannel, _ := cache.GetDiscordSession().ChannelMessages(IMAGE_SUGGESTION_CHANNEL, 100, "", "", "")
+	messagesInChannel, _ := cache.GetDiscordSession().ChannelMessages(suggestionChannelID, 100, "", "", "")
 	for _, msg := range messagesInChannel {
 		messagesToDelete = append(messagesToDelete, msg.ID)
 	}
 
-	err := cache.GetDiscordSession().ChannelMessagesBulkDelete(IMAGE_SUGGESTION_CHANNEL, messagesToDelete)
+	err := cache.GetDiscordSession().ChannelMessagesBulkDelete(suggestionChannelID, messagesToDelete)
 	if err != nil {
 		fmt.Println("Error deleting messages: ", err.Error())
 	}
 
 	// make a message on how to edit suggestions
 	helpMessage := "```Editable Fields: name, group, gender, notes\nCommand: !edit {field} new field value...\n\nPlease add a note when denying suggestions.```"
-	utils.SendMessage(IMAGE_SUGGESTION_CHANNEL, helpMessage)
+	utils.SendMessage(suggestionChannelID, helpMessage)
 
 	// load unresolved suggestions and create the first embed
 	loadUnresolvedSuggestions()
 	updateCurrentSuggestionEmbed()
-
-	genderFolderMap = map[string]string{
-		"boy":  BOYS_FOLDER_ID,
-		"girl": GIRLS_FOLDER_ID,
-	}
 }
 
-// processImageSuggestion
-func ProcessImageSuggestion(msg *discordgo.Message, msgContent string, groupIdolMap map[string][]string) {
-	invalidArgsMessage := "Invalid suggestion arguments. \n\n" +
-		"Suggestion must be done with the following format:\n```!biasgame suggest [boy/girl] \"group name\" \"idol name\" [url to image]```\n" +
-		"For Example:\n```!biasgame suggest girl \"PRISTIN\" \"Nayoung\" https://cdn.discordapp.com/attachments/420049316615553026/420056295618510849/unknown.png```\n\n"
-
-	defer func() {
-		if r := recover(); r != nil {
-			// fmt.Println("Panic: ", r)
-			utils.SendMessage(msg.ChannelID, invalidArgsMessage)
-		}
-	}()
-
-	// ToArgv can panic, need to catch that
-	suggestionArgs := str.ToArgv(msgContent)[1:]
-	var suggestedImageUrl string
-
-	// validate suggestion arg amount.
-	if len(msg.Attachments) == 1 {
-		if len(suggestionArgs) != 3 {
-			utils.SendMessage(msg.ChannelID, invalidArgsMessage)
-			return
-		}
-		suggestedImageUrl = msg.Attachments[0].URL
-	} else {
-		if len(suggestionArgs) != 4 {
-			utils.SendMessage(msg.ChannelID, invalidArgsMessage)
-			return
-		}
-		suggestedImageUrl = suggestionArgs[3]
-	}
-
-	// set gender to lowercase and check if its valid
-	suggestionArgs[0] = strings.ToLower(suggestionArgs[0])
-	if suggestionArgs[0] != "girl" && suggestionArgs[0] != "boy" {
-		utils.SendMessage(msg.ChannelID, invalidArgsMessage)
-		return
-	}
+// ProcessImageSuggestion validates the suggested image and adds it to the suggestion queue.
+//  gender must be one of the suggestionGenders values
+func ProcessImageSuggestion(msg *discordgo.Message, gender string, groupName string, idolName string, suggestedImageUrl string, groupIdolMap map[string][]string) {
 
 	// validate url image
 	resp, err := pester.Get(suggestedImageUrl)
@@ -139,7 +123,7 @@ func ProcessImageSuggestion(msg *discordgo.Message, msgContent string, groupIdol
 	}
 
 	// validate group and idol name have no double quotes or underscores
-	if strings.ContainsAny(suggestionArgs[1]+suggestionArgs[2], "\"_") {
+	if strings.ContainsAny(groupName+idolName, "\"_") {
 		utils.SendMessage(msg.ChannelID, "biasgame.suggestion.invalid-group-or-idol")
 		return
 	}
@@ -150,21 +134,21 @@ func ProcessImageSuggestion(msg *discordgo.Message, msgContent string, groupIdol
 	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
 	for k, v := range groupIdolMap {
 		curGroup := strings.ToLower(reg.ReplaceAllString(k, ""))
-		sugGroup := strings.ToLower(reg.ReplaceAllString(suggestionArgs[1], ""))
+		sugGroup := strings.ToLower(reg.ReplaceAllString(groupName, ""))
 
 		// if groups match, set the suggested group to the current group
 		if curGroup == sugGroup {
 			groupMatch = true
-			suggestionArgs[1] = k
+			groupName = k
 
 			// check if the idols name matches
-			for _, idolName := range v {
-				curName := strings.ToLower(reg.ReplaceAllString(idolName, ""))
-				sugName := strings.ToLower(reg.ReplaceAllString(suggestionArgs[2], ""))
+			for _, currentIdolName := range v {
+				curName := strings.ToLower(reg.ReplaceAllString(currentIdolName, ""))
+				sugName := strings.ToLower(reg.ReplaceAllString(idolName, ""))
 
 				if curName == sugName {
 					idolMatch = true
-					suggestionArgs[2] = idolName
+					idolName = currentIdolName
 					break
 				}
 			}
@@ -174,15 +158,15 @@ func ProcessImageSuggestion(msg *discordgo.Message, msgContent string, groupIdol
 
 	// send ty message
 	fmt.Println(msg.Author.Mention())
-	utils.SendMessagef(msg.ChannelID, "biasgame.suggestion.thanks-for-suggestion", msg.Author.Mention())
+	utils.SendMessagef(msg.ChannelID, "biasgame.suggestion.thanks-for-suggestion", msg.Author.Mention(), getBiasGameConfig().Emojis.SuggestionThanks)
 
 	// create suggetion
 	suggestion := &models.BiasGameSuggestionEntry{
 		UserID:     msg.Author.ID,
 		ChannelID:  msg.ChannelID,
-		Gender:     suggestionArgs[0],
-		GrouopName: suggestionArgs[1],
-		Name:       suggestionArgs[2],
+		Gender:     gender,
+		GroupName:  groupName,
+		Name:       idolName,
 		ImageURL:   suggestedImageUrl,
 		GroupMatch: groupMatch,
 		IdolMatch:  idolMatch,
@@ -190,17 +174,20 @@ func ProcessImageSuggestion(msg *discordgo.Message, msgContent string, groupIdol
 
 	// save suggetion to database and memory
 	suggestionQueue = append(suggestionQueue, suggestion)
-	utils.MongoDBInsert(models.BiasGameSuggestionsTable, suggestion)
+	err = cache.GetRepositories().Suggestions.InsertSuggestion(suggestion)
+	if err != nil {
+		fmt.Println("Error saving suggestion: ", err.Error())
+	}
 	updateCurrentSuggestionEmbed()
 
 	// make a message and delete it immediatly. just to show that a new suggestion has come in
-	msg, _ = utils.SendMessage(IMAGE_SUGGESTION_CHANNEL, "New Suggestion Ping")
+	msg, _ = utils.SendMessage(suggestionChannelID, "New Suggestion Ping")
 	go utils.DeleteImageWithDelay(msg, time.Second*2)
 }
 
 // CheckSuggestionReaction will check if the reaction was added to a suggestion message
-func CheckSuggestionReaction(reaction *discordgo.MessageReactionAdd) *drive.File {
-	var approvedFiles *drive.File
+func CheckSuggestionReaction(reaction *discordgo.MessageReactionAdd) *models.SourceImage {
+	var approvedImage *models.SourceImage
 	var userResponseMessage string
 
 	// check if the reaction added was valid
@@ -210,6 +197,12 @@ func CheckSuggestionReaction(reaction *discordgo.MessageReactionAdd) *drive.File
 
 	// check if the reaction was added to the suggestion embed message
 	if reaction.MessageID == suggestionEmbedMessageId {
+
+		// only bot owners and admins of the suggestion channels server can review suggestions
+		if !utils.IsBotOwner(reaction.UserID) && !utils.IsGuildAdmin(reaction.UserID, suggestionChannelID) {
+			return nil
+		}
+
 		if len(suggestionQueue) == 0 {
 			return nil
 		}
@@ -220,22 +213,22 @@ func CheckSuggestionReaction(reaction *discordgo.MessageReactionAdd) *drive.File
 		if CHECKMARK_EMOJI == reaction.Emoji.Name {
 
 			// send processing image message
-			msg, err := utils.SendMessage(IMAGE_SUGGESTION_CHANNEL, "Uploading image to google drive...")
+			msg, err := utils.SendMessage(suggestionChannelID, "Uploading image...")
 			if err == nil {
-				defer cache.GetDiscordSession().ChannelMessageDelete(IMAGE_SUGGESTION_CHANNEL, msg.ID)
+				defer cache.GetDiscordSession().ChannelMessageDelete(suggestionChannelID, msg.ID)
 			}
 
 			// make call to get suggestion image
 			res, err := pester.Get(cs.ImageURL)
 			if err != nil {
-				msg, _ := utils.SendMessage(IMAGE_SUGGESTION_CHANNEL, "biasgame.suggestion.could-not-decode")
+				msg, _ := utils.SendMessage(suggestionChannelID, "biasgame.suggestion.could-not-decode")
 				go utils.DeleteImageWithDelay(msg, time.Second*15)
 				return nil
 			}
 
-			approvedImage, err := utils.DecodeImage(res.Body)
+			decodedImage, err := utils.DecodeImage(res.Body)
 			if err != nil {
-				msg, _ := utils.SendMessage(IMAGE_SUGGESTION_CHANNEL, "biasgame.suggestion.could-not-decode")
+				msg, _ := utils.SendMessage(suggestionChannelID, "biasgame.suggestion.could-not-decode")
 				go utils.DeleteImageWithDelay(msg, time.Second*15)
 				return nil
 			}
@@ -243,34 +236,37 @@ func CheckSuggestionReaction(reaction *discordgo.MessageReactionAdd) *drive.File
 			buf := new(bytes.Buffer)
 			encoder := new(png.Encoder)
 			encoder.CompressionLevel = -2 // -2 compression is best speed
-			encoder.Encode(buf, approvedImage)
+			encoder.Encode(buf, decodedImage)
 			myReader := bytes.NewReader(buf.Bytes())
 
-			// upload image to google drive
-			file_meta := &drive.File{Name: fmt.Sprintf("%s_%s.png", cs.GrouopName, cs.Name), Parents: []string{genderFolderMap[cs.Gender]}}
-			approvedFiles, err = cache.GetGoogleDriveService().Files.Create(file_meta).Media(myReader).Fields(googleapi.Field("name, id, parents, webViewLink, webContentLink")).Do()
+			// save image to the image source
+			sourceImage, err := cache.GetImageSource().AddIdolImage(cs.Gender, cs.GroupName, cs.Name, myReader)
 			if err != nil {
 				fmt.Println("error: ", err.Error())
-				msg, _ := utils.SendMessage(IMAGE_SUGGESTION_CHANNEL, "biasgame.suggestion.drive-upload-failed")
+				msg, _ := utils.SendMessage(suggestionChannelID, "biasgame.suggestion.upload-failed")
 				go utils.DeleteImageWithDelay(msg, time.Second*15)
 				return nil
 			}
+			approvedImage = &sourceImage
 
 			// set image accepted image
-			userResponseMessage = fmt.Sprintf("**Bias Game Suggestion Approved** <:SeemsBlob:422158571115905034>\nIdol: %s %s\nImage: <%s>", cs.GrouopName, cs.Name, cs.ImageURL)
+			userResponseMessage = fmt.Sprintf("**Bias Game Suggestion Approved** %s\nIdol: %s %s\nImage: <%s>", getBiasGameConfig().Emojis.SuggestionApproved, cs.GroupName, cs.Name, cs.ImageURL)
 			cs.Status = "approved"
 
 		} else if X_EMOJI == reaction.Emoji.Name {
 
 			// image was denied
-			userResponseMessage = fmt.Sprintf("**Bias Game Suggestion Denied** <:NotLikeBlob:422163995869315082>\nIdol: %s %s\nImage: <%s>", cs.GrouopName, cs.Name, cs.ImageURL)
+			userResponseMessage = fmt.Sprintf("**Bias Game Suggestion Denied** %s\nIdol: %s %s\nImage: <%s>", getBiasGameConfig().Emojis.SuggestionDenied, cs.GroupName, cs.Name, cs.ImageURL)
 			cs.Status = "denied"
 		}
 
 		// update db record
 		cs.ProcessedByUserId = reaction.UserID
 		cs.LastModifiedOn = time.Now()
-		go utils.MongoDBUpdate(models.BiasGameSuggestionsTable, cs.ID, cs)
+		err := cache.GetRepositories().Suggestions.UpdateSuggestion(cs)
+		if err != nil {
+			fmt.Println("Error saving suggestion: ", err.Error())
+		}
 
 		// send a message to the user who suggested the image
 		dmChannel, err := cache.GetDiscordSession().UserChannelCreate(cs.UserID)
@@ -287,12 +283,12 @@ func CheckSuggestionReaction(reaction *discordgo.MessageReactionAdd) *drive.File
 		go updateCurrentSuggestionEmbed()
 	}
 
-	return approvedFiles
+	return approvedImage
 }
 
 // UpdateSuggestionDetails
 func UpdateSuggestionDetails(msg *discordgo.Message, fieldToUpdate string, value string) {
-	if msg.ChannelID != IMAGE_SUGGESTION_CHANNEL {
+	if msg.ChannelID != suggestionChannelID {
 		return
 	}
 
@@ -309,7 +305,7 @@ func UpdateSuggestionDetails(msg *discordgo.Message, fieldToUpdate string, value
 	case "name":
 		cs.Name = value
 	case "group":
-		cs.GrouopName = value
+		cs.GroupName = value
 	case "gender":
 		cs.Gender = value
 	case "notes":
@@ -319,7 +315,10 @@ func UpdateSuggestionDetails(msg *discordgo.Message, fieldToUpdate string, value
 	}
 
 	// save changes and update embed message
-	utils.MongoDBUpdate(models.BiasGameSuggestionsTable, cs.ID, cs)
+	err := cache.GetRepositories().Suggestions.UpdateSuggestion(cs)
+	if err != nil {
+		fmt.Println("Error saving suggestion: ", err.Error())
+	}
 	updateCurrentSuggestionEmbed()
 }
 
@@ -388,7 +387,7 @@ func updateCurrentSuggestionEmbed() {
 				},
 				{
 					Name:   groupNameDisplay,
-					Value:  cs.GrouopName,
+					Value:  cs.GroupName,
 					Inline: true,
 				},
 				{
@@ -423,32 +422,29 @@ func updateCurrentSuggestionEmbed() {
 	// send or edit embed message
 	var embedMsg *discordgo.Message
 	if suggestionEmbedMessageId == "" {
-		embedMsg, _ = utils.SendEmbed(IMAGE_SUGGESTION_CHANNEL, embed)
+		embedMsg, _ = utils.SendEmbed(suggestionChannelID, embed)
 		suggestionEmbedMessageId = embedMsg.ID
 	} else {
-		embedMsg, _ = utils.EditEmbed(IMAGE_SUGGESTION_CHANNEL, suggestionEmbedMessageId, embed)
+		embedMsg, _ = utils.EditEmbed(suggestionChannelID, suggestionEmbedMessageId, embed)
 	}
 
 	// delete any reactions on message and then reset them if there's another suggestion in queue
-	cache.GetDiscordSession().MessageReactionsRemoveAll(IMAGE_SUGGESTION_CHANNEL, embedMsg.ID)
+	cache.GetDiscordSession().MessageReactionsRemoveAll(suggestionChannelID, embedMsg.ID)
 	if len(suggestionQueue) > 0 {
-		cache.GetDiscordSession().MessageReactionAdd(IMAGE_SUGGESTION_CHANNEL, embedMsg.ID, CHECKMARK_EMOJI)
-		cache.GetDiscordSession().MessageReactionAdd(IMAGE_SUGGESTION_CHANNEL, embedMsg.ID, X_EMOJI)
+		cache.GetDiscordSession().MessageReactionAdd(suggestionChannelID, embedMsg.ID, CHECKMARK_EMOJI)
+		cache.GetDiscordSession().MessageReactionAdd(suggestionChannelID, embedMsg.ID, X_EMOJI)
 	}
 }
 
 // loadUnresolvedSuggestions
 func loadUnresolvedSuggestions() {
-	queryParams := bson.M{}
-
-	queryParams["status"] = ""
+	suggestionQueue = nil
 
-	results := utils.MongoDBSearch(models.BiasGameSuggestionsTable, queryParams)
-
-	resultCount, err := results.Count()
-	if err != nil || resultCount == 0 {
+	suggestions, err := cache.GetRepositories().Suggestions.FindSuggestionsByStatus("")
+	if err != nil {
+		fmt.Println("Error loading unresolved suggestions: ", err.Error())
 		return
 	}
 
-	results.All(&suggestionQueue)
+	suggestionQueue = suggestions
 }
@@ -1,6 +1,7 @@
 package voice
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -8,6 +9,9 @@ import (
 	"net/http"
 	"os/exec"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Snakeyesz/snek-bot/utils"
 	"github.com/bwmarrin/discordgo"
@@ -30,20 +34,24 @@ var (
 
 	// Holds the voiceInstances for all servers the bot is on
 	// serverid: voiceInstance{}
-	voiceInstances = map[string]*voiceInstance{}
+	voiceInstances      = map[string]*voiceInstance{}
+	voiceInstancesMutex sync.Mutex
 )
 
 // VoiceInstance is created for each connected server
 type voiceInstance struct {
-	queue           *lane.Queue
-	voiceConnection *discordgo.VoiceConnection
-	pcmChannel      chan []int16
-	guildID         string
-	skip            bool
-	stop            bool
-	repeat          bool
-	pause           bool
-	trackPlaying    bool
+	queue        *lane.Queue
+	pcmChannel   chan []int16
+	guildID      string
+	skip         bool
+	stop         bool
+	repeat       bool
+	pause        bool
+	trackPlaying int32 // 1 while a song is streaming, read and set atomically
+
+	// the connection is left by the queue or on shutdown, whichever takes it first
+	voiceConnection      *discordgo.VoiceConnection
+	voiceConnectionMutex sync.Mutex
 
 	// the text channel the music was initiated from.
 	// will be used to send error messages or update messages.
@@ -56,6 +64,8 @@ type voiceInstance struct {
 
 // GetOrMakeVoiceInstance will return the voice instance or create one if doesn't exist for the given guild
 func GetOrMakeVoiceInstance(guildID string, msg *discordgo.Message) *voiceInstance {
+	voiceInstancesMutex.Lock()
+	defer voiceInstancesMutex.Unlock()
 
 	// if the voiceinstance exists, return it
 	if vi, ok := voiceInstances[guildID]; ok {
@@ -92,7 +102,7 @@ func (vi *voiceInstance) PlaySongByUrl(url string, msg *discordgo.Message) {
 		return
 	} else {
 		fmt.Println("voice connection successful")
-		vi.voiceConnection = voiceConnection
+		vi.setVoiceConnection(voiceConnection)
 	}
 
 	// add song to queue
@@ -117,6 +127,8 @@ func (vi *voiceInstance) TogglePauseSong(toggle bool) {
 
 // stopSong will stop the current song, voiceinstance queue, and leave the channel
 func (vi *voiceInstance) StopMusic() {
+	// a paused song never checks if it should stop
+	vi.pause = false
 	vi.stop = true
 }
 
@@ -140,14 +152,105 @@ func (vi *voiceInstance) RepeatSong(msg *discordgo.Message) {
 	}
 }
 
+// StopAllMusic will stop the music in every guild and leave all voice channels.
+//  used when the bot is shutting down, returns once the songs have stopped or the context is done
+func StopAllMusic(ctx context.Context) {
+	voiceInstancesMutex.Lock()
+	instances := make([]*voiceInstance, 0, len(voiceInstances))
+	for _, vi := range voiceInstances {
+		instances = append(instances, vi)
+	}
+	voiceInstancesMutex.Unlock()
+
+	for _, vi := range instances {
+		if vi.isTrackPlaying() {
+			utils.SendMessage(vi.textChannelID, "music.bot-shutting-down")
+		}
+
+		vi.StopMusic()
+	}
+
+	// wait for the songs to stop streaming
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+
+waitForSongs:
+	for isAnyTrackPlaying(instances) {
+		select {
+		case <-ctx.Done():
+			break waitForSongs
+		case <-ticker.C:
+		}
+	}
+
+	// leave any channels the bot is still in
+	for _, vi := range instances {
+		if voiceConnection := vi.takeVoiceConnection(); voiceConnection != nil {
+			voiceConnection.Disconnect()
+		}
+	}
+}
+
 ///////////////////////
 // PRIVATE FUNCTIONS //
 ///////////////////////
 
+// isAnyTrackPlaying checks if any of the voice instances is still streaming a song
+func isAnyTrackPlaying(instances []*voiceInstance) bool {
+	for _, vi := range instances {
+		if vi.isTrackPlaying() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTrackPlaying checks if the voice instance is streaming a song
+func (vi *voiceInstance) isTrackPlaying() bool {
+	return atomic.LoadInt32(&vi.trackPlaying) == 1
+}
+
+// setTrackPlaying sets if the voice instance is streaming a song
+func (vi *voiceInstance) setTrackPlaying(playing bool) {
+	if playing {
+		atomic.StoreInt32(&vi.trackPlaying, 1)
+	} else {
+		atomic.StoreInt32(&vi.trackPlaying, 0)
+	}
+}
+
+// getVoiceConnection returns the connection songs are streamed to, nil if the channel was left
+func (vi *voiceInstance) getVoiceConnection() *discordgo.VoiceConnection {
+	vi.voiceConnectionMutex.Lock()
+	defer vi.voiceConnectionMutex.Unlock()
+
+	return vi.voiceConnection
+}
+
+// setVoiceConnection sets the connection songs are streamed to
+func (vi *voiceInstance) setVoiceConnection(voiceConnection *discordgo.VoiceConnection) {
+	vi.voiceConnectionMutex.Lock()
+	defer vi.voiceConnectionMutex.Unlock()
+
+	vi.voiceConnection = voiceConnection
+}
+
+// takeVoiceConnection removes the connection from the voice instance and returns it so it can be left.
+//  returns nil if the connection was already taken
+func (vi *voiceInstance) takeVoiceConnection() *discordgo.VoiceConnection {
+	vi.voiceConnectionMutex.Lock()
+	defer vi.voiceConnectionMutex.Unlock()
+
+	voiceConnection := vi.voiceConnection
+	vi.voiceConnection = nil
+	return voiceConnection
+}
+
 func (vi *voiceInstance) processQueue() {
 	fmt.Println("processing queue")
 
-	if vi.trackPlaying == false {
+	if !vi.isTrackPlaying() {
 
 		// runs for each song in queue
 		for {
@@ -182,7 +285,9 @@ func (vi *voiceInstance) processQueue() {
 		if vi.stop == true {
 			close(vi.pcmChannel)
 			vi.pcmChannel = nil
-			vi.voiceConnection.Disconnect()
+			if voiceConnection := vi.takeVoiceConnection(); voiceConnection != nil {
+				voiceConnection.Disconnect()
+			}
 		}
 	}
 
@@ -196,13 +301,20 @@ func (vi *voiceInstance) resetVoiceInstance() {
 	vi.pause = false
 	vi.repeat = false
 	vi.skip = false
-	vi.trackPlaying = false
+	vi.setTrackPlaying(false)
 }
 
 // startAudioStreamFromUrl will start reading the buffer
 func (vi *voiceInstance) startAudioStreamFromUrl(url string) {
 	fmt.Println("playing form url")
-	vi.trackPlaying = true
+	vi.setTrackPlaying(true)
+	defer vi.setTrackPlaying(false)
+
+	// the channel was left while the song was starting
+	voiceConnection := vi.getVoiceConnection()
+	if voiceConnection == nil {
+		return
+	}
 
 	// get the audio data from the given song url
 	resp, err := http.Get(url)
@@ -215,7 +327,7 @@ func (vi *voiceInstance) startAudioStreamFromUrl(url string) {
 	// if the pcm channel is not yet running, start it
 	if vi.pcmChannel == nil {
 		vi.pcmChannel = make(chan []int16, 1)
-		go sendPCM(vi.voiceConnection, vi.pcmChannel)
+		go sendPCM(voiceConnection, vi.pcmChannel)
 	}
 
 	// option details can be found https://ffmpeg.org/ffmpeg-all.html
@@ -247,13 +359,13 @@ func (vi *voiceInstance) startAudioStreamFromUrl(url string) {
 	audiobuf := make([]int16, frameSize*audioChannels)
 
 	// show and allow the bot to output audio
-	err = vi.voiceConnection.Speaking(true)
+	err = voiceConnection.Speaking(true)
 	if err != nil {
 		// alert user bot can't properly output audio and stop music
 		utils.SendMessage(vi.textChannelID, "bot.voice.bot-cant-speak")
 		vi.stop = true
 	}
-	defer vi.voiceConnection.Speaking(false)
+	defer voiceConnection.Speaking(false)
 
 	for {
 
@@ -291,7 +403,6 @@ func (vi *voiceInstance) startAudioStreamFromUrl(url string) {
 	}
 
 	fmt.Println("read loop ended")
-	vi.trackPlaying = false
 }
 
 // sendPCM will create the pcm channel which sends opus data to discord from the pcm channel which will be loaded with audio
@@ -0,0 +1,51 @@
+package modules
+
+import (
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/Snakeyesz/snek-bot/modules/commands"
+	"github.com/Snakeyesz/snek-bot/modules/plugins"
+	"github.com/Snakeyesz/snek-bot/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// checkGuildRestrictions checks if the guild has disabled the command or its plugin, or doesn't allow it in the channel.
+//   returns the i18n key of the denial message if the command can't be used. disabled commands return an empty key
+//   so the bot stays quiet in servers that turned them off
+func checkGuildRestrictions(command *commands.Command, msg *discordgo.Message) (string, bool) {
+	guildID := utils.GetGuildIDFromMessage(msg)
+
+	// settings commands can never be restricted so admins can't lock themselves out
+	if guildID == "" || command.Plugin() == plugins.SETTINGS_PLUGIN_NAME {
+		return "", true
+	}
+
+	settings := utils.GetGuildSettings(guildID)
+
+	if utils.ContainsString(settings.DisabledPlugins, command.Plugin()) {
+		return "", false
+	}
+	if isChannelRestricted(settings.ChannelRestrictions["plugin:"+command.Plugin()], msg.ChannelID) {
+		return "restrictions.channel-not-allowed", false
+	}
+
+	// disabling or restricting a command also applies to its sub commands
+	for checkCommand := command; checkCommand != nil; checkCommand = checkCommand.Parent() {
+		if utils.ContainsString(settings.DisabledCommands, checkCommand.Path()) {
+			return "", false
+		}
+		if isChannelRestricted(settings.ChannelRestrictions["command:"+checkCommand.Path()], msg.ChannelID) {
+			return "restrictions.channel-not-allowed", false
+		}
+	}
+
+	return "", true
+}
+
+// isChannelRestricted checks if the channel is denied, or if only other channels are allowed
+func isChannelRestricted(restriction models.ChannelRestriction, channelID string) bool {
+	if utils.ContainsString(restriction.DeniedChannelIDs, channelID) {
+		return true
+	}
+
+	return len(restriction.AllowedChannelIDs) > 0 && !utils.ContainsString(restriction.AllowedChannelIDs, channelID)
+}
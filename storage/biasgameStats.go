@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+// countBiases counts the winners or losers of the games in go, used by the backends that can't aggregate
+func countBiases(games []models.BiasGameEntry, query models.BiasGameStatsQuery) models.BiasGameStats {
+	stats := models.BiasGameStats{TotalGames: len(games)}
+
+	counts := make(map[models.BiasCount]int)
+	for _, game := range games {
+		var biases []models.BiasEntry
+
+		switch query.Count {
+		case models.COUNT_ROUND_WINNERS:
+			biases = game.RoundWinners
+		case models.COUNT_ROUND_LOSERS:
+			biases = game.RoundLosers
+		default:
+			biases = []models.BiasEntry{game.GameWinner}
+		}
+
+		for _, bias := range biases {
+			// incomplete games have no game winner
+			if bias.GroupName == "" {
+				continue
+			}
+
+			key := models.BiasCount{GroupName: bias.GroupName}
+			if !query.ByGroup {
+				key.Name = bias.Name
+			}
+			counts[key]++
+		}
+	}
+
+	for key, count := range counts {
+		key.Count = count
+		stats.Counts = append(stats.Counts, key)
+	}
+	sortBiasCounts(stats.Counts)
+
+	if query.Limit > 0 && len(stats.Counts) > query.Limit {
+		stats.Counts = stats.Counts[:query.Limit]
+	}
+
+	return stats
+}
+
+// rankUsers ranks the users of the games in go, used by the backends that can't aggregate
+func rankUsers(games []models.BiasGameEntry, limit int) []models.UserRanking {
+	idolWins := make(map[string]map[models.BiasEntry]int) // userID => game winner => wins
+	for _, game := range games {
+		winner := models.BiasEntry{GroupName: game.GameWinner.GroupName, Name: game.GameWinner.Name}
+
+		if idolWins[game.UserID] == nil {
+			idolWins[game.UserID] = make(map[models.BiasEntry]int)
+		}
+		idolWins[game.UserID][winner]++
+	}
+
+	var rankings []models.UserRanking
+	for userID, wins := range idolWins {
+		var idols []models.BiasCount
+		ranking := models.UserRanking{UserID: userID}
+
+		for idol, count := range wins {
+			ranking.TotalGames += count
+			idols = append(idols, models.BiasCount{GroupName: idol.GroupName, Name: idol.Name, Count: count})
+		}
+
+		sortBiasCounts(idols)
+		ranking.TopIdol = models.BiasEntry{GroupName: idols[0].GroupName, Name: idols[0].Name}
+		ranking.TopIdolWins = idols[0].Count
+
+		rankings = append(rankings, ranking)
+	}
+
+	sort.Slice(rankings, func(i, j int) bool {
+		if rankings[i].TotalGames != rankings[j].TotalGames {
+			return rankings[i].TotalGames > rankings[j].TotalGames
+		}
+		return rankings[i].UserID < rankings[j].UserID
+	})
+
+	if limit > 0 && len(rankings) > limit {
+		rankings = rankings[:limit]
+	}
+
+	return rankings
+}
+
+// sortBiasCounts sorts the most counted first, ties are sorted by group and name
+func sortBiasCounts(counts []models.BiasCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].GroupName != counts[j].GroupName {
+			return counts[i].GroupName < counts[j].GroupName
+		}
+		return counts[i].Name < counts[j].Name
+	})
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+var (
+	nayeon = models.BiasEntry{GroupName: "Twice", Name: "Nayeon"}
+	momo   = models.BiasEntry{GroupName: "Twice", Name: "Momo"}
+	irene  = models.BiasEntry{GroupName: "Red Velvet", Name: "Irene"}
+)
+
+// statsTestGames has nayeon winning twice and irene once, every round is won by the game winner
+var statsTestGames = []models.BiasGameEntry{
+	{UserID: "1", GameWinner: nayeon, RoundWinners: []models.BiasEntry{nayeon, nayeon}, RoundLosers: []models.BiasEntry{momo, irene}},
+	{UserID: "1", GameWinner: nayeon, RoundWinners: []models.BiasEntry{nayeon}, RoundLosers: []models.BiasEntry{irene}},
+	{UserID: "2", GameWinner: irene, RoundWinners: []models.BiasEntry{irene}, RoundLosers: []models.BiasEntry{momo}},
+}
+
+func TestCountBiases(t *testing.T) {
+	tests := []struct {
+		name  string
+		query models.BiasGameStatsQuery
+		want  []models.BiasCount
+	}{
+		{
+			"game winners",
+			models.BiasGameStatsQuery{},
+			[]models.BiasCount{{GroupName: "Twice", Name: "Nayeon", Count: 2}, {GroupName: "Red Velvet", Name: "Irene", Count: 1}},
+		},
+		{
+			"round winners",
+			models.BiasGameStatsQuery{Count: models.COUNT_ROUND_WINNERS},
+			[]models.BiasCount{{GroupName: "Twice", Name: "Nayeon", Count: 3}, {GroupName: "Red Velvet", Name: "Irene", Count: 1}},
+		},
+		{
+			"round losers, ties sorted by group and name",
+			models.BiasGameStatsQuery{Count: models.COUNT_ROUND_LOSERS},
+			[]models.BiasCount{{GroupName: "Red Velvet", Name: "Irene", Count: 2}, {GroupName: "Twice", Name: "Momo", Count: 2}},
+		},
+		{
+			"round losers by group",
+			models.BiasGameStatsQuery{Count: models.COUNT_ROUND_LOSERS, ByGroup: true},
+			[]models.BiasCount{{GroupName: "Red Velvet", Count: 2}, {GroupName: "Twice", Count: 2}},
+		},
+		{
+			"limit",
+			models.BiasGameStatsQuery{Count: models.COUNT_ROUND_WINNERS, Limit: 1},
+			[]models.BiasCount{{GroupName: "Twice", Name: "Nayeon", Count: 3}},
+		},
+	}
+
+	for _, test := range tests {
+		stats := countBiases(statsTestGames, test.query)
+		if stats.TotalGames != len(statsTestGames) {
+			t.Errorf("%s: expected %d total games, got %d", test.name, len(statsTestGames), stats.TotalGames)
+		}
+		if !reflect.DeepEqual(stats.Counts, test.want) {
+			t.Errorf("%s: expected counts %v, got %v", test.name, test.want, stats.Counts)
+		}
+	}
+}
+
+func TestCountBiasesIncomplete(t *testing.T) {
+	incompleteGames := append(append([]models.BiasGameEntry{}, statsTestGames...), models.BiasGameEntry{
+		UserID:       "3",
+		Incomplete:   true,
+		RoundWinners: []models.BiasEntry{momo},
+		RoundLosers:  []models.BiasEntry{nayeon},
+	})
+
+	winners := countBiases(incompleteGames, models.BiasGameStatsQuery{})
+	if winners.TotalGames != 4 || len(winners.Counts) != 2 {
+		t.Errorf("an incomplete game should only count toward the total games, got %v", winners)
+	}
+
+	roundWinners := countBiases(incompleteGames, models.BiasGameStatsQuery{Count: models.COUNT_ROUND_WINNERS})
+	want := []models.BiasCount{{GroupName: "Twice", Name: "Nayeon", Count: 3}, {GroupName: "Red Velvet", Name: "Irene", Count: 1}, {GroupName: "Twice", Name: "Momo", Count: 1}}
+	if !reflect.DeepEqual(roundWinners.Counts, want) {
+		t.Errorf("expected the rounds of the incomplete game to be counted %v, got %v", want, roundWinners.Counts)
+	}
+}
+
+func TestRankUsers(t *testing.T) {
+	want := []models.UserRanking{
+		{UserID: "1", TotalGames: 2, TopIdol: nayeon, TopIdolWins: 2},
+		{UserID: "2", TotalGames: 1, TopIdol: irene, TopIdolWins: 1},
+	}
+
+	if rankings := rankUsers(statsTestGames, 0); !reflect.DeepEqual(rankings, want) {
+		t.Errorf("expected rankings %v, got %v", want, rankings)
+	}
+	if rankings := rankUsers(statsTestGames, 1); !reflect.DeepEqual(rankings, want[:1]) {
+		t.Errorf("expected only the top ranking, got %v", rankings)
+	}
+
+	// users with the same amount of games are sorted by id
+	tiedGames := append(append([]models.BiasGameEntry{}, statsTestGames...), models.BiasGameEntry{UserID: "0", GameWinner: momo})
+	if rankings := rankUsers(tiedGames, 0); rankings[1].UserID != "0" || rankings[2].UserID != "2" {
+		t.Errorf("tied users were not sorted by id: %v", rankings)
+	}
+}
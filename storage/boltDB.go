@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/globalsign/mgo/bson"
+	bolt "go.etcd.io/bbolt"
+)
+
+// buckets use the same names as the mongo collections
+var boltDBBuckets = []models.MongoDbCollection{
+	models.BiasGameTable,
+	models.BiasGameSuggestionsTable,
+	models.BiasGameSnapshotsTable,
+	models.GuildSettingsTable,
+	models.IdolRatingsTable,
+	models.MigrationsTable,
+}
+
+// CreateBoltDBBuckets creates any bucket the repositories use that doesn't exist yet
+func CreateBoltDBBuckets(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range boltDBBuckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// NewBoltDBRepositories returns repositories that use the cached bolt database.
+//  records are saved as json, games and suggestions are keyed by id, guild settings by guild id,
+//  idol ratings by their scope and idol, and game snapshots by game key
+func NewBoltDBRepositories() *models.Repositories {
+	return &models.Repositories{
+		Games:         &boltDBGames{},
+		Suggestions:   &boltDBSuggestions{},
+		GuildSettings: &boltDBGuildSettings{},
+		Ratings:       &boltDBRatings{},
+		Snapshots:     &boltDBSnapshots{},
+	}
+}
+
+////////////////
+// BIAS GAMES //
+////////////////
+
+type boltDBGames struct{}
+
+func (r *boltDBGames) InsertGame(game *models.BiasGameEntry) error {
+	if game.ID == "" {
+		game.ID = bson.NewObjectId()
+	}
+
+	return boltDBPut(models.BiasGameTable, string(game.ID), game)
+}
+
+func (r *boltDBGames) FindGames(filter models.BiasGameFilter) ([]models.BiasGameEntry, error) {
+	var games []models.BiasGameEntry
+	err := boltDBForEach(models.BiasGameTable, func(data []byte) error {
+		var game models.BiasGameEntry
+		if err := json.Unmarshal(data, &game); err != nil {
+			return err
+		}
+
+		if filter.Matches(game) {
+			games = append(games, game)
+		}
+		return nil
+	})
+
+	return games, err
+}
+
+func (r *boltDBGames) CountBiases(query models.BiasGameStatsQuery) (models.BiasGameStats, error) {
+	games, err := r.FindGames(query.Filter)
+	if err != nil {
+		return models.BiasGameStats{}, err
+	}
+
+	return countBiases(games, query), nil
+}
+
+func (r *boltDBGames) RankUsers(filter models.BiasGameFilter, limit int) ([]models.UserRanking, error) {
+	games, err := r.FindGames(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankUsers(games, limit), nil
+}
+
+/////////////////
+// SUGGESTIONS //
+/////////////////
+
+type boltDBSuggestions struct{}
+
+func (r *boltDBSuggestions) InsertSuggestion(suggestion *models.BiasGameSuggestionEntry) error {
+	if suggestion.ID == "" {
+		suggestion.ID = bson.NewObjectId()
+	}
+
+	return boltDBPut(models.BiasGameSuggestionsTable, string(suggestion.ID), suggestion)
+}
+
+func (r *boltDBSuggestions) UpdateSuggestion(suggestion *models.BiasGameSuggestionEntry) error {
+	err := cache.GetBoltDB().View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(models.BiasGameSuggestionsTable)).Get([]byte(suggestion.ID)) == nil {
+			return models.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return boltDBPut(models.BiasGameSuggestionsTable, string(suggestion.ID), suggestion)
+}
+
+func (r *boltDBSuggestions) FindSuggestionsByStatus(status string) ([]*models.BiasGameSuggestionEntry, error) {
+	var suggestions []*models.BiasGameSuggestionEntry
+	err := boltDBForEach(models.BiasGameSuggestionsTable, func(data []byte) error {
+		suggestion := &models.BiasGameSuggestionEntry{}
+		if err := json.Unmarshal(data, suggestion); err != nil {
+			return err
+		}
+
+		if suggestion.Status == status {
+			suggestions = append(suggestions, suggestion)
+		}
+		return nil
+	})
+
+	return suggestions, err
+}
+
+//////////////////
+// IDOL RATINGS //
+//////////////////
+
+type boltDBRatings struct{}
+
+func (r *boltDBRatings) FindRatings(filter models.IdolRatingFilter, limit int) ([]models.IdolRatingEntry, error) {
+	var ratings []models.IdolRatingEntry
+	err := boltDBForEach(models.IdolRatingsTable, func(data []byte) error {
+		var rating models.IdolRatingEntry
+		if err := json.Unmarshal(data, &rating); err != nil {
+			return err
+		}
+
+		if filter.Matches(rating) {
+			ratings = append(ratings, rating)
+		}
+		return nil
+	})
+
+	return sortIdolRatings(ratings, limit), err
+}
+
+func (r *boltDBRatings) SaveRatings(ratings []models.IdolRatingEntry) error {
+
+	// save every rating in one transaction, bolt syncs to disk on each commit
+	return cache.GetBoltDB().Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(models.IdolRatingsTable))
+		for _, rating := range ratings {
+			key := []byte(rating.Key())
+
+			var savedRating models.IdolRatingEntry
+			if data := bucket.Get(key); data != nil && json.Unmarshal(data, &savedRating) == nil {
+				rating.ID = savedRating.ID
+			} else if rating.ID == "" {
+				rating.ID = bson.NewObjectId()
+			}
+
+			data, err := json.Marshal(rating)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(key, data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *boltDBRatings) DeleteRatings() error {
+	return cache.GetBoltDB().Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(models.IdolRatingsTable)); err != nil {
+			return err
+		}
+
+		_, err := tx.CreateBucket([]byte(models.IdolRatingsTable))
+		return err
+	})
+}
+
+////////////////////
+// GAME SNAPSHOTS //
+////////////////////
+
+type boltDBSnapshots struct{}
+
+func (r *boltDBSnapshots) FindGameSnapshots() ([]models.BiasGameSnapshotEntry, error) {
+	var snapshots []models.BiasGameSnapshotEntry
+	err := boltDBForEach(models.BiasGameSnapshotsTable, func(data []byte) error {
+		var snapshot models.BiasGameSnapshotEntry
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return err
+		}
+
+		snapshots = append(snapshots, snapshot)
+		return nil
+	})
+
+	return snapshots, err
+}
+
+func (r *boltDBSnapshots) SaveGameSnapshot(snapshot *models.BiasGameSnapshotEntry) error {
+	if snapshot.ID == "" {
+		snapshot.ID = bson.NewObjectId()
+	}
+
+	return boltDBPut(models.BiasGameSnapshotsTable, snapshot.GameKey, snapshot)
+}
+
+func (r *boltDBSnapshots) DeleteGameSnapshot(gameKey string) error {
+	return cache.GetBoltDB().Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(models.BiasGameSnapshotsTable)).Delete([]byte(gameKey))
+	})
+}
+
+////////////////////
+// GUILD SETTINGS //
+////////////////////
+
+type boltDBGuildSettings struct{}
+
+func (r *boltDBGuildSettings) FindGuildSettings(guildID string) (models.GuildSettingsEntry, error) {
+	var settings models.GuildSettingsEntry
+	err := cache.GetBoltDB().View(func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(models.GuildSettingsTable)).Get([]byte(guildID))
+		if data == nil {
+			return models.ErrRecordNotFound
+		}
+
+		return json.Unmarshal(data, &settings)
+	})
+
+	return settings, err
+}
+
+func (r *boltDBGuildSettings) SaveGuildSettings(settings *models.GuildSettingsEntry) error {
+	if settings.ID == "" {
+		settings.ID = bson.NewObjectId()
+	}
+
+	return boltDBPut(models.GuildSettingsTable, settings.GuildID, settings)
+}
+
+/////////////
+// HELPERS //
+/////////////
+
+// boltDBPut saves the record as json under the key, replacing any record already saved there
+func boltDBPut(bucket models.MongoDbCollection, key string, record interface{}) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return cache.GetBoltDB().Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).Put([]byte(key), data)
+	})
+}
+
+// boltDBPutAll saves the json records under their keys in one transaction, replacing any records already saved there
+func boltDBPutAll(bucket models.MongoDbCollection, records map[string][]byte) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	return cache.GetBoltDB().Update(func(tx *bolt.Tx) error {
+		boltBucket := tx.Bucket([]byte(bucket))
+		for key, data := range records {
+			if err := boltBucket.Put([]byte(key), data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// boltDBForEach calls fn with every record in the bucket in key order.
+//  object ids start with their creation time so records come back oldest first
+func boltDBForEach(bucket models.MongoDbCollection, fn func(data []byte) error) error {
+	return cache.GetBoltDB().View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).ForEach(func(key []byte, data []byte) error {
+			return fn(data)
+		})
+	})
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/sethgrid/pester"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	DRIVE_SEARCH_TEXT = "\"%s\" in parents and (mimeType = \"image/gif\" or mimeType = \"image/jpeg\" or mimeType = \"image/png\" or mimeType = \"application/vnd.google-apps.folder\")"
+	DRIVE_FILE_FIELDS = "name, id, parents, webViewLink, webContentLink"
+)
+
+// NewGoogleDriveImageSource returns an image source that uses the biasgame drive folders in the config.
+//  idol images are named Group_Name.png, an idol can have many files with the same name
+func NewGoogleDriveImageSource() models.ImageSource {
+	return &googleDriveImages{}
+}
+
+type googleDriveImages struct{}
+
+func (s *googleDriveImages) ListIdolImages() ([]models.SourceImage, error) {
+	config := cache.GetAppConfig().BiasGame
+
+	girlFiles, err := getDriveFolderFiles(config.GirlsFolderID)
+	if err != nil {
+		return nil, err
+	}
+	boyFiles, err := getDriveFolderFiles(config.BoysFolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []models.SourceImage
+	for _, file := range girlFiles {
+		if image, ok := makeDriveIdolImage(file, "girl"); ok {
+			images = append(images, image)
+		}
+	}
+	for _, file := range boyFiles {
+		if image, ok := makeDriveIdolImage(file, "boy"); ok {
+			images = append(images, image)
+		}
+	}
+
+	return images, nil
+}
+
+func (s *googleDriveImages) ListMiscImages() ([]models.SourceImage, error) {
+	files, err := getDriveFolderFiles(cache.GetAppConfig().BiasGame.MiscFolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []models.SourceImage
+	for _, file := range files {
+		images = append(images, makeDriveImage(file))
+	}
+
+	return images, nil
+}
+
+func (s *googleDriveImages) FetchImage(image models.SourceImage) (io.ReadCloser, error) {
+	res, err := pester.Get(image.ContentURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// drive sends an html page for files that can't be downloaded
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
+		return nil, fmt.Errorf("error downloading drive file %s: %s", image.FileName, res.Status)
+	}
+
+	return res.Body, nil
+}
+
+func (s *googleDriveImages) AddIdolImage(gender string, groupName string, name string, data io.Reader) (models.SourceImage, error) {
+	fileMeta := &drive.File{Name: fmt.Sprintf("%s_%s.png", groupName, name), Parents: []string{getDriveGenderFolderID(gender)}}
+
+	file, err := cache.GetGoogleDriveService().Files.Create(fileMeta).Media(data).Fields(googleapi.Field(DRIVE_FILE_FIELDS)).Do()
+	if err != nil {
+		return models.SourceImage{}, err
+	}
+
+	image, ok := makeDriveIdolImage(file, gender)
+	if !ok {
+		return models.SourceImage{}, fmt.Errorf("drive file %s is not named Group_Name", file.Name)
+	}
+
+	return image, nil
+}
+
+func (s *googleDriveImages) RemoveIdolImage(image models.SourceImage) error {
+	return cache.GetGoogleDriveService().Files.Delete(image.ID).Do()
+}
+
+// getDriveFolderFiles returns every image file in the drive folder
+func getDriveFolderFiles(folderID string) ([]*drive.File, error) {
+	driveService := cache.GetGoogleDriveService()
+
+	results, err := driveService.Files.List().Q(fmt.Sprintf(DRIVE_SEARCH_TEXT, folderID)).Fields(googleapi.Field("nextPageToken, files(" + DRIVE_FILE_FIELDS + ")")).PageSize(1000).Do()
+	if err != nil {
+		return nil, fmt.Errorf("error getting google drive files from folderid %s: %s", folderID, err.Error())
+	}
+	allFiles := results.Files
+
+	// retry for more images if needed
+	pageToken := results.NextPageToken
+	for pageToken != "" {
+		results, err = driveService.Files.List().Q(fmt.Sprintf(DRIVE_SEARCH_TEXT, folderID)).Fields(googleapi.Field("nextPageToken, files(" + DRIVE_FILE_FIELDS + ")")).PageSize(1000).PageToken(pageToken).Do()
+		if err != nil {
+			return nil, fmt.Errorf("error getting google drive files from folderid %s: %s", folderID, err.Error())
+		}
+
+		pageToken = results.NextPageToken
+		if len(results.Files) > 0 {
+			allFiles = append(allFiles, results.Files...)
+		} else {
+			break
+		}
+	}
+
+	return allFiles, nil
+}
+
+// makeDriveImage converts the drive file to an image of the source
+func makeDriveImage(file *drive.File) models.SourceImage {
+	return models.SourceImage{
+		ID:         file.Id,
+		FileName:   file.Name,
+		ViewURL:    file.WebViewLink,
+		ContentURL: file.WebContentLink,
+	}
+}
+
+// makeDriveIdolImage converts the drive file to an idol image, the idol is read from the file name
+func makeDriveIdolImage(file *drive.File, gender string) (models.SourceImage, bool) {
+	groupName, name, ok := parseIdolName(trimImageExtension(file.Name))
+	if !ok {
+		return models.SourceImage{}, false
+	}
+
+	image := makeDriveImage(file)
+	image.GroupName = groupName
+	image.Name = name
+	image.Gender = gender
+
+	return image, true
+}
+
+// getDriveGenderFolderID returns the drive folder of the idol images for the gender
+func getDriveGenderFolderID(gender string) string {
+	if gender == "boy" {
+		return cache.GetAppConfig().BiasGame.BoysFolderID
+	}
+
+	return cache.GetAppConfig().BiasGame.GirlsFolderID
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// file types the image sources load, the same types the drive search allows
+var imageExtensions = map[string]bool{
+	".gif":  true,
+	".jpeg": true,
+	".jpg":  true,
+	".png":  true,
+}
+
+// isImageFile checks if the file name has an image extension
+func isImageFile(fileName string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(fileName))]
+}
+
+// trimImageExtension removes the image extension from the file name.
+//  other extensions are kept since group names can have dots in them
+func trimImageExtension(fileName string) string {
+	if !isImageFile(fileName) {
+		return fileName
+	}
+
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName))
+}
+
+// parseIdolName gets the group and idol name from a name in the Group_Name format
+func parseIdolName(groupIdol string) (string, string, bool) {
+	names := strings.Split(groupIdol, "_")
+	if len(names) < 2 || names[0] == "" || names[1] == "" {
+		return "", "", false
+	}
+
+	return names[0], names[1], true
+}
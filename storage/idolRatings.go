@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"sort"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+// sortIdolRatings sorts the ratings highest first like the mongo query, then cuts them down to limit ratings. zero for all
+func sortIdolRatings(ratings []models.IdolRatingEntry, limit int) []models.IdolRatingEntry {
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		if ratings[i].GroupName != ratings[j].GroupName {
+			return ratings[i].GroupName < ratings[j].GroupName
+		}
+		return ratings[i].Name < ratings[j].Name
+	})
+
+	if limit > 0 && len(ratings) > limit {
+		ratings = ratings[:limit]
+	}
+
+	return ratings
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+const (
+	LOCAL_MISC_IMAGES_FOLDER = "misc"
+)
+
+// folders of the idol images of each gender, in the order they're listed
+var localGenderFolders = []struct {
+	gender string
+	folder string
+}{
+	{"girl", "girls"},
+	{"boy", "boys"},
+}
+
+// NewLocalImageSource returns an image source that uses the images in the folder, so the game can run without google drive.
+//  each idol has a folder of their images, girls/Group_Name/ or boys/Group_Name/. misc game images are in misc/
+func NewLocalImageSource(path string) models.ImageSource {
+	return &localImages{path: path}
+}
+
+type localImages struct {
+	sync.Mutex // held while images are added or removed
+	path       string
+}
+
+func (s *localImages) ListIdolImages() ([]models.SourceImage, error) {
+	if _, err := os.Stat(s.path); err != nil {
+		return nil, err
+	}
+
+	var images []models.SourceImage
+	for _, genderFolder := range localGenderFolders {
+		idolFolders, err := ioutil.ReadDir(filepath.Join(s.path, genderFolder.folder))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, idolFolder := range idolFolders {
+			if !idolFolder.IsDir() {
+				continue
+			}
+
+			groupName, name, ok := parseIdolName(idolFolder.Name())
+			if !ok {
+				continue
+			}
+
+			imagePaths, err := s.listImageFiles(filepath.Join(genderFolder.folder, idolFolder.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			for _, imagePath := range imagePaths {
+				images = append(images, models.SourceImage{
+					ID:        imagePath,
+					FileName:  idolFolder.Name(),
+					GroupName: groupName,
+					Name:      name,
+					Gender:    genderFolder.gender,
+				})
+			}
+		}
+	}
+
+	return images, nil
+}
+
+func (s *localImages) ListMiscImages() ([]models.SourceImage, error) {
+	imagePaths, err := s.listImageFiles(LOCAL_MISC_IMAGES_FOLDER)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []models.SourceImage
+	for _, imagePath := range imagePaths {
+		images = append(images, models.SourceImage{ID: imagePath, FileName: filepath.Base(imagePath)})
+	}
+
+	return images, nil
+}
+
+func (s *localImages) FetchImage(image models.SourceImage) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.path, image.ID))
+}
+
+func (s *localImages) AddIdolImage(gender string, groupName string, name string, data io.Reader) (models.SourceImage, error) {
+	if strings.ContainsAny(groupName+name, `_/\`) {
+		return models.SourceImage{}, errors.New("group and idol names can't have _, /, or \\ in them")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	image := models.SourceImage{
+		FileName:  groupName + "_" + name,
+		GroupName: groupName,
+		Name:      name,
+		Gender:    gender,
+	}
+
+	idolFolder := filepath.Join(getLocalGenderFolder(gender), image.FileName)
+	if err := os.MkdirAll(filepath.Join(s.path, idolFolder), 0755); err != nil {
+		return models.SourceImage{}, err
+	}
+
+	image.ID = filepath.Join(idolFolder, fmt.Sprintf("%d.png", time.Now().UnixNano()))
+	file, err := os.Create(filepath.Join(s.path, image.ID))
+	if err != nil {
+		return models.SourceImage{}, err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, data); err != nil {
+		os.Remove(file.Name())
+		return models.SourceImage{}, err
+	}
+
+	return image, nil
+}
+
+func (s *localImages) RemoveIdolImage(image models.SourceImage) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := os.Remove(filepath.Join(s.path, image.ID)); err != nil {
+		return err
+	}
+
+	// the idol folder is only removed once it's empty
+	os.Remove(filepath.Join(s.path, filepath.Dir(image.ID)))
+	return nil
+}
+
+// listImageFiles returns the paths of the image files in the folder, relative to the images folder
+func (s *localImages) listImageFiles(folder string) ([]string, error) {
+	files, err := ioutil.ReadDir(filepath.Join(s.path, folder))
+	if err != nil {
+		return nil, err
+	}
+
+	var imagePaths []string
+	for _, file := range files {
+		if !file.IsDir() && isImageFile(file.Name()) {
+			imagePaths = append(imagePaths, filepath.Join(folder, file.Name()))
+		}
+	}
+
+	return imagePaths, nil
+}
+
+// getLocalGenderFolder returns the folder of the idol images for the gender
+func getLocalGenderFolder(gender string) string {
+	if gender == "boy" {
+		return "boys"
+	}
+
+	return "girls"
+}
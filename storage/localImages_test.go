@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+// makeTestImagesFolder makes an images folder with an idol of each gender and a misc image
+func makeTestImagesFolder(t *testing.T) string {
+	path, err := ioutil.TempDir("", "biasgame-images")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"girls/Twice_Nayeon/1.png":     "nayeon",
+		"girls/Twice_Nayeon/2.jpg":     "nayeon 2",
+		"girls/Twice_Nayeon/notes.txt": "not an image",
+		"girls/NoGroup/1.png":          "not named Group_Name",
+		"boys/BTS_Jin/1.png":           "jin",
+		"misc/verses.png":              "verses",
+	}
+	for file, data := range files {
+		filePath := filepath.Join(path, file)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return path
+}
+
+func TestLocalImagesList(t *testing.T) {
+	path := makeTestImagesFolder(t)
+	defer os.RemoveAll(path)
+	source := NewLocalImageSource(path)
+
+	images, err := source.ListIdolImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 3 {
+		t.Fatalf("expected 3 idol images, got %d: %v", len(images), images)
+	}
+
+	genders := map[string]string{}
+	for _, image := range images {
+		genders[image.GroupName+" "+image.Name] = image.Gender
+	}
+	if genders["Twice Nayeon"] != "girl" || genders["BTS Jin"] != "boy" {
+		t.Errorf("idols were not listed with their gender: %v", genders)
+	}
+
+	miscImages, err := source.ListMiscImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(miscImages) != 1 || miscImages[0].FileName != "verses.png" {
+		t.Errorf("expected only verses.png as a misc image, got %v", miscImages)
+	}
+
+	if _, err := NewLocalImageSource(filepath.Join(path, "missing")).ListIdolImages(); err == nil {
+		t.Error("listing a folder that doesn't exist should fail")
+	}
+}
+
+func TestLocalImagesFetch(t *testing.T) {
+	path := makeTestImagesFolder(t)
+	defer os.RemoveAll(path)
+	source := NewLocalImageSource(path)
+
+	imageData, err := source.FetchImage(models.SourceImage{ID: filepath.Join("boys", "BTS_Jin", "1.png")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer imageData.Close()
+
+	data, err := ioutil.ReadAll(imageData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "jin" {
+		t.Errorf("fetched the wrong image data: %s", data)
+	}
+}
+
+func TestLocalImagesAddAndRemove(t *testing.T) {
+	path := makeTestImagesFolder(t)
+	defer os.RemoveAll(path)
+	source := NewLocalImageSource(path)
+
+	if _, err := source.AddIdolImage("girl", "Red_Velvet", "Irene", bytes.NewBufferString("irene")); err == nil {
+		t.Error("an idol with _ in their group name should not be added")
+	}
+
+	image, err := source.AddIdolImage("girl", "Red Velvet", "Irene", bytes.NewBufferString("irene"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.FileName != "Red Velvet_Irene" || image.Gender != "girl" {
+		t.Errorf("added image has the wrong idol: %v", image)
+	}
+
+	images, err := source.ListIdolImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed bool
+	for _, listedImage := range images {
+		if listedImage == image {
+			listed = true
+		}
+	}
+	if !listed {
+		t.Errorf("added image %v was not listed", image)
+	}
+
+	if err := source.RemoveIdolImage(image); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "girls", image.FileName)); !os.IsNotExist(err) {
+		t.Error("the idol folder should be removed with its last image")
+	}
+
+	// the idol keeps their folder while they have other images
+	if err := source.RemoveIdolImage(models.SourceImage{ID: filepath.Join("girls", "Twice_Nayeon", "1.png")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "girls", "Twice_Nayeon", "2.jpg")); err != nil {
+		t.Error("removing an image removed the other images of the idol")
+	}
+}
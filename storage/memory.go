@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/globalsign/mgo/bson"
+)
+
+// NewMemoryRepositories returns repositories that only keep records in memory.
+//  used to run the bot locally without a database and in plugin tests, everything is lost when the bot closes
+func NewMemoryRepositories() *models.Repositories {
+	return &models.Repositories{
+		Games:         &memoryGames{},
+		Suggestions:   &memorySuggestions{suggestions: make(map[bson.ObjectId]models.BiasGameSuggestionEntry)},
+		GuildSettings: &memoryGuildSettings{settings: make(map[string]models.GuildSettingsEntry)},
+		Ratings:       &memoryRatings{ratings: make(map[string]models.IdolRatingEntry)},
+		Snapshots:     &memorySnapshots{snapshots: make(map[string]models.BiasGameSnapshotEntry)},
+	}
+}
+
+////////////////
+// BIAS GAMES //
+////////////////
+
+type memoryGames struct {
+	sync.RWMutex
+	games []models.BiasGameEntry
+}
+
+func (r *memoryGames) InsertGame(game *models.BiasGameEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if game.ID == "" {
+		game.ID = bson.NewObjectId()
+	}
+	r.games = append(r.games, *game)
+
+	return nil
+}
+
+func (r *memoryGames) FindGames(filter models.BiasGameFilter) ([]models.BiasGameEntry, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var games []models.BiasGameEntry
+	for _, game := range r.games {
+		if filter.Matches(game) {
+			games = append(games, game)
+		}
+	}
+
+	return games, nil
+}
+
+func (r *memoryGames) CountBiases(query models.BiasGameStatsQuery) (models.BiasGameStats, error) {
+	games, err := r.FindGames(query.Filter)
+	if err != nil {
+		return models.BiasGameStats{}, err
+	}
+
+	return countBiases(games, query), nil
+}
+
+func (r *memoryGames) RankUsers(filter models.BiasGameFilter, limit int) ([]models.UserRanking, error) {
+	games, err := r.FindGames(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return rankUsers(games, limit), nil
+}
+
+/////////////////
+// SUGGESTIONS //
+/////////////////
+
+type memorySuggestions struct {
+	sync.RWMutex
+	suggestions map[bson.ObjectId]models.BiasGameSuggestionEntry
+	order       []bson.ObjectId // insert order, so suggestions are returned oldest first like mongo
+}
+
+func (r *memorySuggestions) InsertSuggestion(suggestion *models.BiasGameSuggestionEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if suggestion.ID == "" {
+		suggestion.ID = bson.NewObjectId()
+	}
+	if _, ok := r.suggestions[suggestion.ID]; ok {
+		return errors.New("duplicate id")
+	}
+
+	r.suggestions[suggestion.ID] = *suggestion
+	r.order = append(r.order, suggestion.ID)
+
+	return nil
+}
+
+func (r *memorySuggestions) UpdateSuggestion(suggestion *models.BiasGameSuggestionEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.suggestions[suggestion.ID]; !ok {
+		return models.ErrRecordNotFound
+	}
+	r.suggestions[suggestion.ID] = *suggestion
+
+	return nil
+}
+
+func (r *memorySuggestions) FindSuggestionsByStatus(status string) ([]*models.BiasGameSuggestionEntry, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var suggestions []*models.BiasGameSuggestionEntry
+	for _, id := range r.order {
+		suggestion := r.suggestions[id]
+		if suggestion.Status == status {
+			suggestions = append(suggestions, &suggestion)
+		}
+	}
+
+	return suggestions, nil
+}
+
+//////////////////
+// IDOL RATINGS //
+//////////////////
+
+type memoryRatings struct {
+	sync.RWMutex
+	ratings map[string]models.IdolRatingEntry // rating key => rating
+}
+
+func (r *memoryRatings) FindRatings(filter models.IdolRatingFilter, limit int) ([]models.IdolRatingEntry, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var ratings []models.IdolRatingEntry
+	for _, rating := range r.ratings {
+		if filter.Matches(rating) {
+			ratings = append(ratings, rating)
+		}
+	}
+
+	return sortIdolRatings(ratings, limit), nil
+}
+
+func (r *memoryRatings) SaveRatings(ratings []models.IdolRatingEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, rating := range ratings {
+		if savedRating, ok := r.ratings[rating.Key()]; ok {
+			rating.ID = savedRating.ID
+		} else if rating.ID == "" {
+			rating.ID = bson.NewObjectId()
+		}
+		r.ratings[rating.Key()] = rating
+	}
+
+	return nil
+}
+
+func (r *memoryRatings) DeleteRatings() error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.ratings = make(map[string]models.IdolRatingEntry)
+	return nil
+}
+
+////////////////////
+// GAME SNAPSHOTS //
+////////////////////
+
+type memorySnapshots struct {
+	sync.RWMutex
+	snapshots map[string]models.BiasGameSnapshotEntry // game key => snapshot
+}
+
+func (r *memorySnapshots) FindGameSnapshots() ([]models.BiasGameSnapshotEntry, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var snapshots []models.BiasGameSnapshotEntry
+	for _, snapshot := range r.snapshots {
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+func (r *memorySnapshots) SaveGameSnapshot(snapshot *models.BiasGameSnapshotEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if savedSnapshot, ok := r.snapshots[snapshot.GameKey]; ok {
+		snapshot.ID = savedSnapshot.ID
+	} else if snapshot.ID == "" {
+		snapshot.ID = bson.NewObjectId()
+	}
+	r.snapshots[snapshot.GameKey] = *snapshot
+
+	return nil
+}
+
+func (r *memorySnapshots) DeleteGameSnapshot(gameKey string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.snapshots, gameKey)
+	return nil
+}
+
+////////////////////
+// GUILD SETTINGS //
+////////////////////
+
+type memoryGuildSettings struct {
+	sync.RWMutex
+	settings map[string]models.GuildSettingsEntry // guildID => settings
+}
+
+func (r *memoryGuildSettings) FindGuildSettings(guildID string) (models.GuildSettingsEntry, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	settings, ok := r.settings[guildID]
+	if !ok {
+		return models.GuildSettingsEntry{}, models.ErrRecordNotFound
+	}
+
+	return settings.Copy(), nil
+}
+
+func (r *memoryGuildSettings) SaveGuildSettings(settings *models.GuildSettingsEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if settings.ID == "" {
+		settings.ID = bson.NewObjectId()
+	}
+	r.settings[settings.GuildID] = settings.Copy()
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+func TestMemoryGamesFindGames(t *testing.T) {
+	repositories := NewMemoryRepositories()
+	now := time.Now()
+
+	games := []models.BiasGameEntry{
+		{UserID: "1", GuildID: "a", GameType: "single", GameWinner: models.BiasEntry{Gender: "girl"}, CreatedAt: now.Add(-time.Hour * 48)},
+		{UserID: "1", GuildID: "b", GameType: "single", GameWinner: models.BiasEntry{Gender: "boy"}, CreatedAt: now.Add(-time.Hour)},
+		{UserID: "2", GuildID: "a", GameType: "single", Incomplete: true, CreatedAt: now.Add(-time.Hour)},
+		{GuildID: "a", GameType: "multi", GameWinner: models.BiasEntry{Gender: "girl"}, CreatedAt: now},
+	}
+	for i := range games {
+		if err := repositories.Games.InsertGame(&games[i]); err != nil {
+			t.Fatal(err)
+		}
+		if games[i].ID == "" {
+			t.Error("inserted game was not given an id")
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter models.BiasGameFilter
+		want   int
+	}{
+		{"no filter leaves out incomplete games", models.BiasGameFilter{}, 3},
+		{"incomplete games", models.BiasGameFilter{IncludeIncomplete: true}, 4},
+		{"game type", models.BiasGameFilter{GameType: "multi"}, 1},
+		{"guild", models.BiasGameFilter{GuildID: "a", IncludeIncomplete: true}, 3},
+		{"user", models.BiasGameFilter{UserID: "1"}, 2},
+		{"winner gender", models.BiasGameFilter{WinnerGender: "girl"}, 2},
+		{"created from", models.BiasGameFilter{CreatedFrom: now.Add(-time.Hour), IncludeIncomplete: true}, 3},
+		{"created to is exclusive", models.BiasGameFilter{CreatedTo: now}, 2},
+		{"created between", models.BiasGameFilter{CreatedFrom: now.Add(-time.Hour * 24), CreatedTo: now}, 1},
+		{"every field", models.BiasGameFilter{GameType: "single", GuildID: "b", UserID: "1", WinnerGender: "boy"}, 1},
+		{"no matches", models.BiasGameFilter{UserID: "3"}, 0},
+	}
+
+	for _, test := range tests {
+		found, err := repositories.Games.FindGames(test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != test.want {
+			t.Errorf("%s: expected %d games, got %d", test.name, test.want, len(found))
+		}
+	}
+}
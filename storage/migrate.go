@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	MIGRATE_BATCH_SIZE = 1000 // records written to bolt in each transaction, bolt syncs to disk on each commit
+)
+
+// MigrateMongoDBToBoltDB copies the biasgame, suggestion, and guild settings collections from the cached mongo database
+//  into the cached bolt database. returns the amount of records copied from each collection.
+//  records keep their ids, so running it again replaces the copied records instead of duplicating them
+func MigrateMongoDBToBoltDB() (map[models.MongoDbCollection]int, error) {
+	copied := make(map[models.MongoDbCollection]int)
+
+	var err error
+	copied[models.BiasGameTable], err = migrateMongoDBCollection(models.BiasGameTable, func(iter *mgo.Iter) (string, interface{}, bool) {
+		var game models.BiasGameEntry
+		ok := iter.Next(&game)
+		return string(game.ID), game, ok
+	})
+	if err != nil {
+		return copied, err
+	}
+
+	copied[models.BiasGameSuggestionsTable], err = migrateMongoDBCollection(models.BiasGameSuggestionsTable, func(iter *mgo.Iter) (string, interface{}, bool) {
+		var suggestion models.BiasGameSuggestionEntry
+		ok := iter.Next(&suggestion)
+		return string(suggestion.ID), suggestion, ok
+	})
+	if err != nil {
+		return copied, err
+	}
+
+	copied[models.GuildSettingsTable], err = migrateMongoDBCollection(models.GuildSettingsTable, func(iter *mgo.Iter) (string, interface{}, bool) {
+		var settings models.GuildSettingsEntry
+		ok := iter.Next(&settings)
+		return settings.GuildID, settings, ok
+	})
+
+	return copied, err
+}
+
+// migrateMongoDBCollection streams the records of the collection into the bolt bucket of the same name, a batch at a time.
+//  next reads the next record from the iterator and returns its bolt key, it returns false once there are no records left.
+//  returns the amount of records copied
+func migrateMongoDBCollection(collection models.MongoDbCollection, next func(iter *mgo.Iter) (string, interface{}, bool)) (int, error) {
+	copied := 0
+
+	err := withMongoDB(func(db *mgo.Database) error {
+		iter := db.C(collection.String()).Find(bson.M{}).Iter()
+
+		batch := make(map[string][]byte)
+		for {
+			key, record, ok := next(iter)
+			if !ok {
+				break
+			}
+
+			data, err := json.Marshal(record)
+			if err != nil {
+				iter.Close()
+				return err
+			}
+			batch[key] = data
+
+			if len(batch) >= MIGRATE_BATCH_SIZE {
+				if err := boltDBPutAll(collection, batch); err != nil {
+					iter.Close()
+					return err
+				}
+				copied += len(batch)
+				batch = make(map[string][]byte)
+			}
+		}
+
+		// the iterator holds the error that ended it early
+		if err := iter.Close(); err != nil {
+			return err
+		}
+
+		if err := boltDBPutAll(collection, batch); err != nil {
+			return err
+		}
+		copied += len(batch)
+
+		return nil
+	})
+
+	return copied, err
+}
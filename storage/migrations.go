@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	bolt "go.etcd.io/bbolt"
+)
+
+// migration changes how records are saved in a storage backend. each migration is applied once, in version order.
+//  a backend without a func for the migration has nothing to change, the migration is still recorded as applied
+type migration struct {
+	version int
+	name    string
+	mongoDB func(db *mgo.Database) error
+	boltDB  func(tx *bolt.Tx) error
+}
+
+// migrations in version order. never change or remove an applied migration, add a new one instead
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "add biasgame stats, suggestion status, and guild settings indexes",
+		mongoDB: func(db *mgo.Database) error {
+			indexes := []struct {
+				collection models.MongoDbCollection
+				index      mgo.Index
+			}{
+				{models.BiasGameTable, mgo.Index{Key: []string{"gametype", "userid"}, Background: true}},
+				{models.BiasGameTable, mgo.Index{Key: []string{"gametype", "guildid"}, Background: true}},
+				{models.BiasGameTable, mgo.Index{Key: []string{"gamewinner.gender"}, Background: true}},
+				{models.BiasGameSuggestionsTable, mgo.Index{Key: []string{"status"}, Background: true}},
+				{models.GuildSettingsTable, mgo.Index{Key: []string{"guildid"}, Unique: true, Background: true}},
+			}
+
+			for _, index := range indexes {
+				if err := db.C(index.collection.String()).EnsureIndex(index.index); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version: 2,
+		name:    "rename suggestion grouopname to groupname",
+		mongoDB: func(db *mgo.Database) error {
+			return renameMongoDBField(db, models.BiasGameSuggestionsTable, "grouopname", "groupname")
+		},
+		boltDB: func(tx *bolt.Tx) error {
+			return renameBoltDBField(tx, models.BiasGameSuggestionsTable, "GrouopName", "GroupName")
+		},
+	},
+	{
+		version: 3,
+		name:    "backfill biasgame createdat from the game id and index it",
+		mongoDB: func(db *mgo.Database) error {
+			games := db.C(models.BiasGameTable.String())
+
+			// object ids start with the time the record was made, close enough to when the game finished
+			var game struct {
+				ID bson.ObjectId `bson:"_id"`
+			}
+			items := games.Find(bson.M{"createdat": bson.M{"$exists": false}}).Select(bson.M{"_id": 1}).Iter()
+			for items.Next(&game) {
+				err := games.UpdateId(game.ID, bson.M{"$set": bson.M{"createdat": game.ID.Time()}})
+				if err != nil {
+					items.Close()
+					return err
+				}
+			}
+			if err := items.Close(); err != nil {
+				return err
+			}
+
+			return games.EnsureIndex(mgo.Index{Key: []string{"createdat"}, Background: true})
+		},
+		boltDB: func(tx *bolt.Tx) error {
+			return updateBoltDBRecords(tx, models.BiasGameTable, func(record map[string]json.RawMessage) bool {
+				if _, ok := record["CreatedAt"]; ok {
+					return false
+				}
+
+				var id bson.ObjectId
+				if err := json.Unmarshal(record["ID"], &id); err != nil || !id.Valid() {
+					return false
+				}
+
+				createdAt, err := json.Marshal(id.Time())
+				if err != nil {
+					return false
+				}
+				record["CreatedAt"] = createdAt
+				return true
+			})
+		},
+	},
+	{
+		version: 4,
+		name:    "add idol rating indexes",
+		mongoDB: func(db *mgo.Database) error {
+			ratings := db.C(models.IdolRatingsTable.String())
+
+			err := ratings.EnsureIndex(mgo.Index{Key: []string{"scope", "scopeid", "groupname", "name"}, Unique: true, Background: true})
+			if err != nil {
+				return err
+			}
+
+			return ratings.EnsureIndex(mgo.Index{Key: []string{"scope", "scopeid", "-rating"}, Background: true})
+		},
+	},
+	{
+		version: 5,
+		name:    "add biasgame snapshot game key index",
+		mongoDB: func(db *mgo.Database) error {
+			return db.C(models.BiasGameSnapshotsTable.String()).EnsureIndex(mgo.Index{Key: []string{"gamekey"}, Unique: true, Background: true})
+		},
+	},
+}
+
+// RunMongoDBMigrations applies the migrations the cached mongo database doesn't have yet
+func RunMongoDBMigrations() error {
+	return withMongoDB(func(db *mgo.Database) error {
+		migrationsCollection := db.C(models.MigrationsTable.String())
+
+		err := migrationsCollection.EnsureIndex(mgo.Index{Key: []string{"version"}, Unique: true})
+		if err != nil {
+			return err
+		}
+
+		var appliedMigrations []models.MigrationEntry
+		err = migrationsCollection.Find(bson.M{}).All(&appliedMigrations)
+		if err != nil {
+			return err
+		}
+
+		applied := make(map[int]bool)
+		for _, appliedMigration := range appliedMigrations {
+			applied[appliedMigration.Version] = true
+		}
+
+		for _, m := range migrations {
+			if applied[m.version] {
+				continue
+			}
+
+			fmt.Printf("Applying mongo migration %d: %s\n", m.version, m.name)
+			if m.mongoDB != nil {
+				if err := m.mongoDB(db); err != nil {
+					return fmt.Errorf("mongo migration %d failed: %s", m.version, err.Error())
+				}
+			}
+
+			err = migrationsCollection.Insert(newMigrationEntry(m))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RunBoltDBMigrations applies the migrations the cached bolt database doesn't have yet.
+//  each migration and its record are saved in one transaction so a failed migration changes nothing
+func RunBoltDBMigrations() error {
+	for _, m := range migrations {
+		err := cache.GetBoltDB().Update(func(tx *bolt.Tx) error {
+			migrationsBucket := tx.Bucket([]byte(models.MigrationsTable))
+
+			key := []byte(fmt.Sprintf("%08d", m.version))
+			if migrationsBucket.Get(key) != nil {
+				return nil
+			}
+
+			fmt.Printf("Applying bolt migration %d: %s\n", m.version, m.name)
+			if m.boltDB != nil {
+				if err := m.boltDB(tx); err != nil {
+					return err
+				}
+			}
+
+			data, err := json.Marshal(newMigrationEntry(m))
+			if err != nil {
+				return err
+			}
+			return migrationsBucket.Put(key, data)
+		})
+		if err != nil {
+			return fmt.Errorf("bolt migration %d failed: %s", m.version, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// newMigrationEntry returns the record of the migration being applied
+func newMigrationEntry(m migration) *models.MigrationEntry {
+	return &models.MigrationEntry{
+		Version:   m.version,
+		Name:      m.name,
+		AppliedOn: time.Now(),
+	}
+}
+
+// renameMongoDBField renames the field in every record of the collection that has it
+func renameMongoDBField(db *mgo.Database, collection models.MongoDbCollection, from string, to string) error {
+	_, err := db.C(collection.String()).UpdateAll(
+		bson.M{from: bson.M{"$exists": true}},
+		bson.M{"$rename": bson.M{from: to}},
+	)
+	return err
+}
+
+// renameBoltDBField renames the json field in every record of the bucket that has it
+func renameBoltDBField(tx *bolt.Tx, bucket models.MongoDbCollection, from string, to string) error {
+	return updateBoltDBRecords(tx, bucket, func(record map[string]json.RawMessage) bool {
+		value, ok := record[from]
+		if !ok {
+			return false
+		}
+
+		record[to] = value
+		delete(record, from)
+		return true
+	})
+}
+
+// updateBoltDBRecords calls update with the json fields of every record in the bucket.
+//  records are saved again when update returns true, used by migrations to rename or backfill fields
+func updateBoltDBRecords(tx *bolt.Tx, bucket models.MongoDbCollection, update func(record map[string]json.RawMessage) bool) error {
+	b := tx.Bucket([]byte(bucket))
+	updated := make(map[string][]byte)
+
+	// bolt doesn't allow changing a bucket while looping over it, so changes are saved after
+	err := b.ForEach(func(key []byte, data []byte) error {
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+
+		if !update(record) {
+			return nil
+		}
+
+		updatedData, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		updated[string(key)] = updatedData
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for key, data := range updated {
+		if err := b.Put([]byte(key), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
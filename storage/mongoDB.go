@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+// NewMongoDBRepositories returns repositories that use the cached mongo database
+func NewMongoDBRepositories() *models.Repositories {
+	return &models.Repositories{
+		Games:         &mongoDBGames{},
+		Suggestions:   &mongoDBSuggestions{},
+		GuildSettings: &mongoDBGuildSettings{},
+		Ratings:       &mongoDBRatings{},
+		Snapshots:     &mongoDBSnapshots{},
+	}
+}
+
+// ratings are saved in batches, mongo limits how many writes one bulk request can have
+const MONGO_DB_BULK_SIZE = 1000
+
+////////////////
+// BIAS GAMES //
+////////////////
+
+type mongoDBGames struct{}
+
+func (r *mongoDBGames) InsertGame(game *models.BiasGameEntry) error {
+	_, err := mongoDBInsert(models.BiasGameTable, game)
+	return err
+}
+
+func (r *mongoDBGames) FindGames(filter models.BiasGameFilter) ([]models.BiasGameEntry, error) {
+	var games []models.BiasGameEntry
+	err := mongoDBFindAll(models.BiasGameTable, mongoDBGameQuery(filter), &games)
+	return games, err
+}
+
+func (r *mongoDBGames) CountBiases(query models.BiasGameStatsQuery) (models.BiasGameStats, error) {
+	stats := models.BiasGameStats{}
+	match := mongoDBGameQuery(query.Filter)
+
+	// round winners and losers are lists, unwind them so each round is counted
+	field := "$gamewinner"
+	pipeline := []bson.M{{"$match": match}}
+	switch query.Count {
+	case models.COUNT_ROUND_WINNERS, models.COUNT_ROUND_LOSERS:
+		field = "$" + query.Count
+		pipeline = append(pipeline, bson.M{"$unwind": field})
+	default:
+		// incomplete games have no game winner
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"gamewinner.groupname": bson.M{"$ne": ""}}})
+	}
+
+	groupBy := bson.M{"group": field + ".groupname"}
+	if !query.ByGroup {
+		groupBy["name"] = field + ".name"
+	}
+	pipeline = append(pipeline,
+		bson.M{"$group": bson.M{"_id": groupBy, "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Name: "count", Value: -1}, {Name: "_id.group", Value: 1}, {Name: "_id.name", Value: 1}}},
+	)
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": query.Limit})
+	}
+
+	var results []struct {
+		ID struct {
+			Group string `bson:"group"`
+			Name  string `bson:"name"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+
+	err := withMongoDB(func(db *mgo.Database) error {
+		var err error
+		stats.TotalGames, err = db.C(models.BiasGameTable.String()).Find(match).Count()
+		if err != nil {
+			return err
+		}
+
+		return db.C(models.BiasGameTable.String()).Pipe(pipeline).AllowDiskUse().All(&results)
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	for _, result := range results {
+		stats.Counts = append(stats.Counts, models.BiasCount{GroupName: result.ID.Group, Name: result.ID.Name, Count: result.Count})
+	}
+
+	return stats, nil
+}
+
+func (r *mongoDBGames) RankUsers(filter models.BiasGameFilter, limit int) ([]models.UserRanking, error) {
+
+	// count each users wins per idol, then keep the idol with the most wins while totaling the users games
+	pipeline := []bson.M{
+		{"$match": mongoDBGameQuery(filter)},
+		{"$group": bson.M{
+			"_id":  bson.M{"user": "$userid", "group": "$gamewinner.groupname", "name": "$gamewinner.name"},
+			"wins": bson.M{"$sum": 1},
+		}},
+		{"$sort": bson.D{{Name: "wins", Value: -1}, {Name: "_id.group", Value: 1}, {Name: "_id.name", Value: 1}}},
+		{"$group": bson.M{
+			"_id":     "$_id.user",
+			"games":   bson.M{"$sum": "$wins"},
+			"group":   bson.M{"$first": "$_id.group"},
+			"name":    bson.M{"$first": "$_id.name"},
+			"topwins": bson.M{"$first": "$wins"},
+		}},
+		{"$sort": bson.D{{Name: "games", Value: -1}, {Name: "_id", Value: 1}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": limit})
+	}
+
+	var results []struct {
+		UserID  string `bson:"_id"`
+		Games   int    `bson:"games"`
+		Group   string `bson:"group"`
+		Name    string `bson:"name"`
+		TopWins int    `bson:"topwins"`
+	}
+
+	err := withMongoDB(func(db *mgo.Database) error {
+		return db.C(models.BiasGameTable.String()).Pipe(pipeline).AllowDiskUse().All(&results)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rankings []models.UserRanking
+	for _, result := range results {
+		rankings = append(rankings, models.UserRanking{
+			UserID:      result.UserID,
+			TotalGames:  result.Games,
+			TopIdol:     models.BiasEntry{GroupName: result.Group, Name: result.Name},
+			TopIdolWins: result.TopWins,
+		})
+	}
+
+	return rankings, nil
+}
+
+// mongoDBGameQuery returns the mongo query of the filter
+func mongoDBGameQuery(filter models.BiasGameFilter) bson.M {
+	queryParams := bson.M{}
+	if filter.GameType != "" {
+		queryParams["gametype"] = filter.GameType
+	}
+	if filter.GuildID != "" {
+		queryParams["guildid"] = filter.GuildID
+	}
+	if filter.UserID != "" {
+		queryParams["userid"] = filter.UserID
+	}
+	if filter.WinnerGender != "" {
+		queryParams["gamewinner.gender"] = filter.WinnerGender
+	}
+	if !filter.CreatedFrom.IsZero() || !filter.CreatedTo.IsZero() {
+		createdAt := bson.M{}
+		if !filter.CreatedFrom.IsZero() {
+			createdAt["$gte"] = filter.CreatedFrom
+		}
+		if !filter.CreatedTo.IsZero() {
+			createdAt["$lt"] = filter.CreatedTo
+		}
+		queryParams["createdat"] = createdAt
+	}
+	if !filter.IncludeIncomplete {
+		queryParams["incomplete"] = bson.M{"$ne": true}
+	}
+
+	return queryParams
+}
+
+/////////////////
+// SUGGESTIONS //
+/////////////////
+
+type mongoDBSuggestions struct{}
+
+func (r *mongoDBSuggestions) InsertSuggestion(suggestion *models.BiasGameSuggestionEntry) error {
+	_, err := mongoDBInsert(models.BiasGameSuggestionsTable, suggestion)
+	return err
+}
+
+func (r *mongoDBSuggestions) UpdateSuggestion(suggestion *models.BiasGameSuggestionEntry) error {
+	return mongoDBUpdate(models.BiasGameSuggestionsTable, suggestion.ID, suggestion)
+}
+
+func (r *mongoDBSuggestions) FindSuggestionsByStatus(status string) ([]*models.BiasGameSuggestionEntry, error) {
+	var suggestions []*models.BiasGameSuggestionEntry
+	err := mongoDBFindAll(models.BiasGameSuggestionsTable, bson.M{"status": status}, &suggestions)
+	return suggestions, err
+}
+
+////////////////////
+// GAME SNAPSHOTS //
+////////////////////
+
+type mongoDBSnapshots struct{}
+
+func (r *mongoDBSnapshots) FindGameSnapshots() ([]models.BiasGameSnapshotEntry, error) {
+	var snapshots []models.BiasGameSnapshotEntry
+	err := mongoDBFindAll(models.BiasGameSnapshotsTable, bson.M{}, &snapshots)
+	return snapshots, err
+}
+
+func (r *mongoDBSnapshots) SaveGameSnapshot(snapshot *models.BiasGameSnapshotEntry) error {
+
+	// the id is left out of the replacement so a snapshot keeps the id it was first saved with
+	replacement := *snapshot
+	replacement.ID = ""
+
+	return withMongoDB(func(db *mgo.Database) error {
+		_, err := db.C(models.BiasGameSnapshotsTable.String()).Upsert(bson.M{"gamekey": snapshot.GameKey}, replacement)
+		return err
+	})
+}
+
+func (r *mongoDBSnapshots) DeleteGameSnapshot(gameKey string) error {
+	return withMongoDB(func(db *mgo.Database) error {
+		_, err := db.C(models.BiasGameSnapshotsTable.String()).RemoveAll(bson.M{"gamekey": gameKey})
+		return err
+	})
+}
+
+////////////////////
+// GUILD SETTINGS //
+////////////////////
+
+type mongoDBGuildSettings struct{}
+
+func (r *mongoDBGuildSettings) FindGuildSettings(guildID string) (models.GuildSettingsEntry, error) {
+	var settings models.GuildSettingsEntry
+	err := withMongoDB(func(db *mgo.Database) error {
+		return db.C(models.GuildSettingsTable.String()).Find(bson.M{"guildid": guildID}).One(&settings)
+	})
+	if err == mgo.ErrNotFound {
+		return settings, models.ErrRecordNotFound
+	}
+
+	return settings, err
+}
+
+func (r *mongoDBGuildSettings) SaveGuildSettings(settings *models.GuildSettingsEntry) error {
+	if settings.ID == "" {
+		_, err := mongoDBInsert(models.GuildSettingsTable, settings)
+		return err
+	}
+
+	return mongoDBUpdate(models.GuildSettingsTable, settings.ID, settings)
+}
+
+//////////////////
+// IDOL RATINGS //
+//////////////////
+
+type mongoDBRatings struct{}
+
+func (r *mongoDBRatings) FindRatings(filter models.IdolRatingFilter, limit int) ([]models.IdolRatingEntry, error) {
+	queryParams := bson.M{"scope": filter.Scope, "scopeid": filter.ScopeID}
+	if filter.Gender != "" {
+		queryParams["gender"] = filter.Gender
+	}
+	if len(filter.Idols) > 0 {
+		var idols []bson.M
+		for _, idol := range filter.Idols {
+			idols = append(idols, bson.M{"groupname": idol.GroupName, "name": idol.Name})
+		}
+		queryParams["$or"] = idols
+	}
+
+	var ratings []models.IdolRatingEntry
+	err := withMongoDB(func(db *mgo.Database) error {
+		return db.C(models.IdolRatingsTable.String()).Find(queryParams).Sort("-rating", "groupname", "name").Limit(limit).All(&ratings)
+	})
+
+	return ratings, err
+}
+
+func (r *mongoDBRatings) SaveRatings(ratings []models.IdolRatingEntry) error {
+	return withMongoDB(func(db *mgo.Database) error {
+		for start := 0; start < len(ratings); start += MONGO_DB_BULK_SIZE {
+			end := start + MONGO_DB_BULK_SIZE
+			if end > len(ratings) {
+				end = len(ratings)
+			}
+
+			bulk := db.C(models.IdolRatingsTable.String()).Bulk()
+			bulk.Unordered()
+			for _, rating := range ratings[start:end] {
+
+				// the id is left out so existing ratings keep theirs and new ratings are given one
+				rating.ID = ""
+				bulk.Upsert(bson.M{
+					"scope":     rating.Scope,
+					"scopeid":   rating.ScopeID,
+					"groupname": rating.GroupName,
+					"name":      rating.Name,
+				}, rating)
+			}
+
+			if _, err := bulk.Run(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *mongoDBRatings) DeleteRatings() error {
+	return withMongoDB(func(db *mgo.Database) error {
+		_, err := db.C(models.IdolRatingsTable.String()).RemoveAll(bson.M{})
+		return err
+	})
+}
+
+/////////////
+// HELPERS //
+/////////////
+
+// withMongoDB calls fn with the cached database on a copy of the cached session.
+//  each operation gets its own copy so goroutines don't share, and wait on, the same socket
+func withMongoDB(fn func(db *mgo.Database) error) error {
+	session := cache.GetMongoDBSession().Copy()
+	defer session.Close()
+
+	return fn(cache.GetMongoDB().With(session))
+}
+
+// mongoDBInsert is a generic insert function that will insert the given data assuming an ID field is passed in the data
+func mongoDBInsert(collection models.MongoDbCollection, rawData interface{}) (recordID bson.ObjectId, err error) {
+
+	// convert the raw interface data to its actual type
+	recordData := reflect.ValueOf(rawData).Elem()
+
+	// confirm data has an ID field
+	idField := recordData.FieldByName("ID")
+	if !idField.IsValid() {
+		return bson.ObjectId(""), errors.New("invalid data")
+	}
+
+	// if the records id field isn't empty, give it an id
+	newID := idField.String()
+	if newID == "" {
+		newID = string(bson.NewObjectId())
+		idField.SetString(newID)
+	}
+
+	// insert record
+	err = withMongoDB(func(db *mgo.Database) error {
+		return db.C(collection.String()).Insert(recordData.Interface())
+	})
+	if err != nil {
+		return bson.ObjectId(""), err
+	}
+
+	return bson.ObjectId(newID), nil
+}
+
+// mongoDBUpdate is a generic update function that will update the given data based on the object id passed
+func mongoDBUpdate(collection models.MongoDbCollection, recordId bson.ObjectId, data interface{}) error {
+	if !recordId.Valid() {
+		return errors.New("invalid id")
+	}
+
+	return withMongoDB(func(db *mgo.Database) error {
+		return db.C(collection.String()).UpdateId(recordId, data)
+	})
+}
+
+// mongoDBFindAll generic search function, decodes every record matching the selection into results
+func mongoDBFindAll(collection models.MongoDbCollection, selection interface{}, results interface{}) error {
+	return withMongoDB(func(db *mgo.Database) error {
+		return db.C(collection.String()).Find(selection).All(results)
+	})
+}
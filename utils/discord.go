@@ -133,6 +133,38 @@ func GetGuildFromMessage(msg *discordgo.Message) (*discordgo.Guild, error) {
 	return guild, nil
 }
 
+// GetGuildIDFromMessage returns the id of the guild the message was sent in, or an empty string for direct messages
+func GetGuildIDFromMessage(msg *discordgo.Message) string {
+	return GetGuildIDFromChannel(msg.ChannelID)
+}
+
+// GetGuildIDFromChannel returns the id of the guild the channel is in, or an empty string for direct message channels
+func GetGuildIDFromChannel(channelID string) string {
+
+	channel, err := cache.GetDiscordSession().State.Channel(channelID)
+	if err != nil {
+		return ""
+	}
+
+	return channel.GuildID
+}
+
+// UserHasPermissions checks if the user has all of the given permissions in the channel
+func UserHasPermissions(userID string, channelID string, permissions int64) bool {
+
+	userPermissions, err := cache.GetDiscordSession().State.UserChannelPermissions(userID, channelID)
+	if err != nil {
+		return false
+	}
+
+	// administrators have every permission
+	if userPermissions&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+
+	return userPermissions&permissions == permissions
+}
+
 // Applies Embed Limits to the given Embed
 // Source: https://discordapp.com/developers/docs/resources/channel#embed-limits
 func TruncateEmbed(embed *discordgo.MessageEmbed) (result *discordgo.MessageEmbed) {
@@ -1,8 +1,68 @@
 package utils
 
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+)
+
+const (
+	MAX_ERROR_DM_LENGTH = 1900 // discord messages are limited to 2000 characters
+)
+
 // Will panic if error is not nil
 func PanicCheck(err error) {
 	if err != nil {
 		panic(err)
 	}
 }
+
+// RecoverPanic recovers from a panic in plugin code so it can't crash the bot. must be called with defer.
+//   the panic is logged with an error id and the context it happened in.
+//   if a channelID is given, the user is shown the error id so the error can be found in the logs
+//   if "discord_bot.dm_errors_to_owners" is true in the config, the stack trace is sent to the bot owners
+func RecoverPanic(channelID string, context string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	errorID := strconv.FormatInt(time.Now().UnixNano(), 36)
+	stack := string(debug.Stack())
+
+	fmt.Printf("Recovered from panic. Error ID: %s\nContext: %s\nPanic: %v\n%s\n", errorID, context, r, stack)
+
+	if channelID != "" {
+		SendMessagef(channelID, "bot.errors.unexpected-error", errorID)
+	}
+
+	if cache.GetAppConfig().DiscordBot.DMErrorsToOwners {
+		go sendErrorToBotOwners(fmt.Sprintf("**Error ID:** %s\n**Context:** %s\n**Panic:** %v\n```%s```", errorID, context, r, stack))
+	}
+}
+
+// sendErrorToBotOwners direct messages the error report to every bot owner
+func sendErrorToBotOwners(report string) {
+	// a panic while reporting must not crash the bot either
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println("Error sending error report to bot owners: ", r)
+		}
+	}()
+
+	if len(report) > MAX_ERROR_DM_LENGTH {
+		report = report[:MAX_ERROR_DM_LENGTH] + "…```"
+	}
+
+	for _, ownerID := range GetBotOwnerIDs() {
+		dmChannel, err := cache.GetDiscordSession().UserChannelCreate(ownerID)
+		if err != nil {
+			continue
+		}
+
+		cache.GetDiscordSession().ChannelMessageSend(dmChannel.ID, report)
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/Snakeyesz/snek-bot/models"
+)
+
+const (
+	DEFAULT_PREFIX    = "!"
+	MAX_PREFIX_LENGTH = 5
+)
+
+var (
+	// map of guildID => settings, loaded from the database the first time a guild is seen
+	guildSettings      = make(map[string]models.GuildSettingsEntry)
+	guildSettingsMutex sync.RWMutex
+)
+
+// GetGuildSettings returns the settings for the given guild. defaults are returned if the guild has no saved settings
+func GetGuildSettings(guildID string) models.GuildSettingsEntry {
+	guildSettingsMutex.RLock()
+	settings, ok := guildSettings[guildID]
+	guildSettingsMutex.RUnlock()
+
+	if ok {
+		return settings.Copy()
+	}
+
+	settings = models.GuildSettingsEntry{GuildID: guildID}
+
+	// direct messages have no guild and will always use the defaults
+	if guildID == "" {
+		return settings
+	}
+
+	savedSettings, err := cache.GetRepositories().GuildSettings.FindGuildSettings(guildID)
+	if err == nil {
+		settings = savedSettings
+	} else if err != models.ErrRecordNotFound {
+		// don't cache on a database error so the settings are loaded again next time
+		fmt.Println("Error loading guild settings: ", err.Error())
+		return settings
+	}
+
+	guildSettingsMutex.Lock()
+	defer guildSettingsMutex.Unlock()
+	guildSettings[guildID] = settings
+
+	return settings.Copy()
+}
+
+// SaveGuildSettings will insert or update the settings for the guild and update the cached settings
+func SaveGuildSettings(settings models.GuildSettingsEntry) error {
+	err := cache.GetRepositories().GuildSettings.SaveGuildSettings(&settings)
+	if err != nil {
+		return err
+	}
+
+	guildSettingsMutex.Lock()
+	defer guildSettingsMutex.Unlock()
+	guildSettings[settings.GuildID] = settings.Copy()
+
+	return nil
+}
+
+// GetGuildPrefix returns the command prefix for the given guild
+func GetGuildPrefix(guildID string) string {
+	prefix := GetGuildSettings(guildID).Prefix
+	if prefix == "" {
+		return DEFAULT_PREFIX
+	}
+
+	return prefix
+}
+
+// GetGuildLocation returns the time zone of the given guild, utc if the guild hasn't set one
+func GetGuildLocation(guildID string) *time.Location {
+	timezone := GetGuildSettings(guildID).Timezone
+	if timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
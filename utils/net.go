@@ -250,7 +250,12 @@ func NetPostUAWithError(url string, data string, useragent string) (result []byt
 
 // GetJSON sends a GET request to $url, parses it and returns the JSON
 func GetJsonFromUrl(url string) (*gabs.Container, error) {
+	response, err := NetGetUAWithError(url, DEFAULT_UA)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse json
-	json, err := gabs.ParseJSON(NetGet(url))
+	json, err := gabs.ParseJSON(response)
 	return json, err
 }
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"github.com/Snakeyesz/snek-bot/cache"
+	"github.com/bwmarrin/discordgo"
+)
+
+// GetBotOwnerIDs returns the user ids of the bot owners set in the config
+func GetBotOwnerIDs() []string {
+	return cache.GetAppConfig().DiscordBot.OwnerIDs
+}
+
+// IsBotOwner checks if the user is one of the bot owners set in the config
+func IsBotOwner(userID string) bool {
+	for _, ownerID := range GetBotOwnerIDs() {
+		if ownerID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsGuildAdmin checks if the user can manage the guild the channel is in, or has one of the guilds admin roles
+func IsGuildAdmin(userID string, channelID string) bool {
+	if UserHasPermissions(userID, channelID, discordgo.PermissionManageServer) {
+		return true
+	}
+
+	guildID := GetGuildIDFromChannel(channelID)
+	if guildID == "" {
+		return false
+	}
+
+	return UserHasAnyRole(guildID, userID, GetGuildSettings(guildID).AdminRoleIDs)
+}
+
+// UserHasAnyRole checks if the user has at least one of the given roles in the guild
+func UserHasAnyRole(guildID string, userID string, roleIDs []string) bool {
+	if len(roleIDs) == 0 {
+		return false
+	}
+
+	// check state first, fall back to asking discord if the member isn't cached
+	member, err := cache.GetDiscordSession().State.Member(guildID, userID)
+	if err != nil {
+		member, err = cache.GetDiscordSession().GuildMember(guildID, userID)
+		if err != nil {
+			return false
+		}
+	}
+
+	for _, memberRoleID := range member.Roles {
+		for _, roleID := range roleIDs {
+			if memberRoleID == roleID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimit is the amount of uses allowed within a time period. uses are refilled evenly over the period
+type RateLimit struct {
+	Uses   int
+	Period time.Duration
+}
+
+// RateLimiter is a token bucket rate limiter keeping a bucket for each key. ex: a user or channel id
+type RateLimiter struct {
+	buckets map[string]*rateLimitBucket
+	mutex   sync.Mutex
+}
+
+type rateLimitBucket struct {
+	tokens     float64
+	lastRefill time.Time
+	period     time.Duration
+}
+
+// NewRateLimiter creates a rate limiter with no buckets
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+// Allow takes a use from the keys bucket if one is available.
+//   if no uses are available, returns false and how long until the next use is available
+func (r *RateLimiter) Allow(key string, limit RateLimit) (bool, time.Duration) {
+	return r.AllowAll(map[string]RateLimit{key: limit})
+}
+
+// AllowAll takes a use from the bucket of every key, but only if all of them have a use available.
+//   if any bucket is empty no uses are taken, and returns false and how long until every bucket has a use
+func (r *RateLimiter) AllowAll(limits map[string]RateLimit) (bool, time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	allowed := true
+	var buckets []*rateLimitBucket
+	var retryAfter time.Duration
+
+	for key, limit := range limits {
+		if limit.Uses <= 0 || limit.Period <= 0 {
+			continue
+		}
+
+		bucket := r.refillBucket(key, limit, now)
+		if bucket.tokens < 1 {
+			allowed = false
+			refillPerSecond := float64(limit.Uses) / limit.Period.Seconds()
+			if bucketRetryAfter := time.Duration((1 - bucket.tokens) / refillPerSecond * float64(time.Second)); bucketRetryAfter > retryAfter {
+				retryAfter = bucketRetryAfter
+			}
+			continue
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	if !allowed {
+		return false, retryAfter
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return true, 0
+}
+
+// refillBucket returns the keys bucket with the tokens refilled for the time passed since the last refill.
+//   should only be called while the mutex is held
+func (r *RateLimiter) refillBucket(key string, limit RateLimit, now time.Time) *rateLimitBucket {
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: float64(limit.Uses), lastRefill: now}
+		r.buckets[key] = bucket
+	}
+	bucket.period = limit.Period
+
+	// limits may have changed so cap to the current limit
+	refillPerSecond := float64(limit.Uses) / limit.Period.Seconds()
+	bucket.tokens += now.Sub(bucket.lastRefill).Seconds() * refillPerSecond
+	if bucket.tokens > float64(limit.Uses) {
+		bucket.tokens = float64(limit.Uses)
+	}
+	bucket.lastRefill = now
+
+	return bucket
+}
+
+// RemoveStaleBuckets removes buckets that have not been used for longer than their period.
+//   those buckets would be full again, so removing them doesn't change any limits
+func (r *RateLimiter) RemoveStaleBuckets() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, bucket := range r.buckets {
+		if time.Since(bucket.lastRefill) > bucket.period {
+			delete(r.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package utils
+
+// ContainsString checks if the value is in the list
+func ContainsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemoveStrings returns the list without any of the values to remove
+func RemoveStrings(list []string, valuesToRemove []string) []string {
+	var result []string
+	for _, item := range list {
+		if !ContainsString(valuesToRemove, item) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}